@@ -0,0 +1,443 @@
+package main
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// archiveBlockSize is the amount read ahead by each ranged read of an archive
+const archiveBlockSize = 64 * 1024
+
+// Archive formats that can be browsed like folders
+const (
+	archiveZip = "zip"
+	archiveTar = "tar"
+)
+
+// archiveEntry is a file or folder inside an archive
+type archiveEntry struct {
+	name       string // Path inside the archive; folders end in "/"
+	size       int64
+	packed     int64 // Compressed size of zip entries
+	method     string
+	modified   time.Time
+	isDir      bool
+	zipFile    *zip.File
+	dataOffset int64 // Offset of the data of plain tar entries
+	ordinal    int   // Position in a compressed tar, to find the entry again
+}
+
+// archiveIndex lists the entries of an archive object
+type archiveIndex struct {
+	key     string
+	format  string
+	codec   string // Compression of a tar archive, "" if plain
+	size    int64
+	entries []archiveEntry
+	reader  *rangeReaderAt
+}
+
+// archiveOpenedMsg carries the index of an archive that was entered
+type archiveOpenedMsg struct {
+	index *archiveIndex
+	err   error
+}
+
+// archiveFormat returns the archive format of a key, or "" if it is not a browsable archive
+func archiveFormat(key string) string {
+	name := decompressedName(key)
+	switch strings.ToLower(filepath.Ext(keyName(name))) {
+	case ".zip", ".jar", ".whl":
+		if name == key {
+			return archiveZip
+		}
+	case ".tar":
+		return archiveTar
+	}
+	return ""
+}
+
+// rangeReaderAt reads an object through ranged GETs, keeping the last block
+// read so that the many small reads of archive parsers become few requests
+type rangeReaderAt struct {
+	client  *S3Client
+	bucket  string
+	key     string
+	version string // Version ID to read, "" for the current object
+	size    int64
+
+	mu         sync.Mutex
+	blockStart int64
+	block      []byte
+}
+
+// ReadAt implements io.ReaderAt
+func (r *rangeReaderAt) ReadAt(p []byte, off int64) (int, error) {
+	if off >= r.size {
+		return 0, io.EOF
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	n := 0
+	for n < len(p) && off < r.size {
+		if r.block == nil || off < r.blockStart || off >= r.blockStart+int64(len(r.block)) {
+			end := off + int64(len(p)-n)
+			if end < off+archiveBlockSize {
+				end = off + archiveBlockSize
+			}
+			if end > r.size {
+				end = r.size
+			}
+			data, err := r.client.GetObjectVersionRange(context.Background(), r.bucket, r.key, r.version, off, end)
+			if err != nil {
+				return n, err
+			}
+			if len(data) == 0 {
+				return n, io.ErrUnexpectedEOF
+			}
+			r.blockStart = off
+			r.block = data
+		}
+		copied := copy(p[n:], r.block[off-r.blockStart:])
+		n += copied
+		off += int64(copied)
+	}
+	if n < len(p) {
+		return n, io.EOF
+	}
+	return n, nil
+}
+
+// openArchive reads the entry list of an archive object. Zip archives are
+// read through their central directory at the end of the object; plain tar
+// archives are walked header by header, skipping the entry data; compressed
+// tar archives have to be decompressed from start to end.
+func (m Model) openArchive(key string, size int64) tea.Cmd {
+	return tea.Cmd(func() tea.Msg {
+		index := &archiveIndex{
+			key:    key,
+			format: archiveFormat(key),
+			codec:  compressionFromName(key, ""),
+			size:   size,
+			reader: &rangeReaderAt{client: m.s3Client, bucket: m.bucket, key: key, size: size},
+		}
+
+		var err error
+		switch {
+		case index.format == archiveZip:
+			err = index.readZip()
+		case index.codec == "":
+			err = index.readTar(io.NewSectionReader(index.reader, 0, size), true)
+		default:
+			var body io.ReadCloser
+			body, err = m.s3Client.GetObjectStream(context.Background(), m.bucket, key)
+			if err != nil {
+				break
+			}
+			reader, closeReader, decompressErr := decompressReader(body, index.codec)
+			if decompressErr != nil {
+				err = decompressErr
+				break
+			}
+			err = index.readTar(reader, false)
+			closeReader()
+		}
+		if err != nil {
+			return archiveOpenedMsg{err: fmt.Errorf("failed to read archive '%s': %w", displayKey(keyName(key)), err)}
+		}
+
+		sort.Slice(index.entries, func(i, j int) bool {
+			return index.entries[i].name < index.entries[j].name
+		})
+		return archiveOpenedMsg{index: index}
+	})
+}
+
+// readZip reads the central directory of a zip archive
+func (a *archiveIndex) readZip() error {
+	zr, err := zip.NewReader(a.reader, a.size)
+	if err != nil {
+		return err
+	}
+
+	for _, file := range zr.File {
+		method := map[uint16]string{zip.Store: "", zip.Deflate: "deflate", 12: "bzip2", 14: "lzma", 93: "zstd"}[file.Method]
+		a.entries = append(a.entries, archiveEntry{
+			name:     strings.TrimPrefix(file.Name, "./"),
+			size:     int64(file.UncompressedSize64),
+			packed:   int64(file.CompressedSize64),
+			method:   method,
+			modified: file.Modified,
+			isDir:    strings.HasSuffix(file.Name, "/"),
+			zipFile:  file,
+		})
+	}
+	return nil
+}
+
+// readTar walks the headers of a tar archive. When reader is seekable (a plain
+// tar read through ranges) the entry data is skipped without being downloaded.
+func (a *archiveIndex) readTar(reader io.Reader, seekable bool) error {
+	tr := tar.NewReader(reader)
+	for ordinal := 0; ; ordinal++ {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		entry := archiveEntry{
+			name:     strings.TrimPrefix(header.Name, "./"),
+			size:     header.Size,
+			modified: header.ModTime,
+			ordinal:  ordinal,
+		}
+		switch header.Typeflag {
+		case tar.TypeDir:
+			entry.isDir = true
+			if !strings.HasSuffix(entry.name, "/") {
+				entry.name += "/"
+			}
+		case tar.TypeReg, tar.TypeChar, tar.TypeBlock, tar.TypeFifo:
+		default:
+			// Links and other special entries have no data to show
+			entry.size = 0
+		}
+		if entry.name == "" {
+			continue
+		}
+		if seekable {
+			offset, err := reader.(io.Seeker).Seek(0, io.SeekCurrent)
+			if err != nil {
+				return err
+			}
+			entry.dataOffset = offset
+		}
+		a.entries = append(a.entries, entry)
+	}
+}
+
+// list returns the entries directly inside folder path of the archive as
+// objects for the browser. Folders that only exist implicitly in the paths of
+// other entries are listed too, with their total size.
+func (a *archiveIndex) list(path string) ([]S3Object, map[string]DirStats) {
+	var objects []S3Object
+	stats := make(map[string]DirStats)
+	seen := make(map[string]bool)
+
+	for _, entry := range a.entries {
+		if !strings.HasPrefix(entry.name, path) || entry.name == path {
+			continue
+		}
+		rest := strings.TrimPrefix(entry.name, path)
+		modified := entry.modified.Format("2006-01-02 15:04:05")
+
+		if i := strings.Index(rest, "/"); i >= 0 {
+			dirKey := path + rest[:i+1]
+			if !seen[dirKey] {
+				seen[dirKey] = true
+				objects = append(objects, S3Object{Key: dirKey, IsDir: true})
+			}
+			dirStats := stats[dirKey]
+			dirStats.Size += entry.size
+			if modified > dirStats.LastModified {
+				dirStats.LastModified = modified
+			}
+			stats[dirKey] = dirStats
+			continue
+		}
+
+		objects = append(objects, S3Object{Key: entry.name, Size: entry.size, LastModified: modified})
+	}
+
+	sort.SliceStable(objects, func(i, j int) bool {
+		return objects[i].IsDir && !objects[j].IsDir
+	})
+	return objects, stats
+}
+
+// entry finds a file entry by name
+func (a *archiveIndex) entry(name string) (archiveEntry, bool) {
+	for _, entry := range a.entries {
+		if entry.name == name && !entry.isDir {
+			return entry, true
+		}
+	}
+	return archiveEntry{}, false
+}
+
+// entryStream returns a stream of the decompressed content of an entry
+func (a *archiveIndex) entryStream(client *S3Client, bucket string, entry archiveEntry) *decompressStream {
+	stream := newStream("archive entry", nil)
+	switch {
+	case a.format == archiveZip:
+		stream.open = func(ctx context.Context) (io.Reader, func(), error) {
+			reader, err := entry.zipFile.Open()
+			if err != nil {
+				return nil, nil, err
+			}
+			return reader, func() { reader.Close() }, nil
+		}
+	case a.codec == "":
+		stream.open = func(ctx context.Context) (io.Reader, func(), error) {
+			return io.NewSectionReader(a.reader, entry.dataOffset, entry.size), func() {}, nil
+		}
+	default:
+		// Compressed tar entries are found again by decompressing up to them
+		stream.open = func(ctx context.Context) (io.Reader, func(), error) {
+			body, err := client.GetObjectStream(ctx, bucket, a.key)
+			if err != nil {
+				return nil, nil, err
+			}
+			reader, closeReader, err := decompressReader(body, a.codec)
+			if err != nil {
+				return nil, nil, err
+			}
+			tr := tar.NewReader(reader)
+			for ordinal := 0; ; ordinal++ {
+				if _, err := tr.Next(); err != nil {
+					closeReader()
+					return nil, nil, fmt.Errorf("entry '%s' not found: %w", displayKey(entry.name), err)
+				}
+				if ordinal == entry.ordinal {
+					return tr, closeReader, nil
+				}
+			}
+		}
+	}
+	return stream
+}
+
+// previewArchiveEntry opens a preview of an entry of the archive being browsed
+func (m Model) previewArchiveEntry(entry archiveEntry) tea.Cmd {
+	archive := m.archive
+	key := archive.key + "/" + entry.name
+	return tea.Cmd(func() tea.Msg {
+		stream := archive.entryStream(m.s3Client, m.bucket, entry)
+		end := int64(previewChunkSize)
+		if end > entry.size {
+			end = entry.size
+		}
+		data, _, err := stream.ReadRange(context.Background(), 0, end)
+		if err != nil {
+			stream.Close()
+			return previewChunkMsg{key: key, mode: chunkOpen, err: err}
+		}
+		return previewChunkMsg{
+			key:        key,
+			size:       entry.size,
+			start:      0,
+			data:       data,
+			mode:       chunkOpen,
+			eof:        true, // The entry size is known from the archive index
+			codec:      entry.method,
+			storedSize: entry.packed,
+			stream:     stream,
+		}
+	})
+}
+
+// extractArchiveEntry writes an entry of the archive being browsed to the current directory
+func (m Model) extractArchiveEntry(entry archiveEntry) tea.Cmd {
+	archive := m.archive
+	return tea.Cmd(func() tea.Msg {
+		stream := archive.entryStream(m.s3Client, m.bucket, entry)
+		reader, closeReader, err := stream.open(context.Background())
+		if err != nil {
+			return fileDownloadedMsg{err: err}
+		}
+		defer closeReader()
+
+		// Get a local filename from the entry name that cannot escape the current directory
+		filename := localFileName(entry.name)
+		file, err := os.Create(filename)
+		if err != nil {
+			return fileDownloadedMsg{err: fmt.Errorf("failed to create file '%s': %w", filename, err)}
+		}
+		if _, err := io.Copy(file, reader); err != nil {
+			file.Close()
+			os.Remove(filename)
+			return fileDownloadedMsg{err: fmt.Errorf("failed to extract '%s': %w", displayKey(entry.name), err)}
+		}
+		if err := file.Close(); err != nil {
+			return fileDownloadedMsg{err: fmt.Errorf("failed to write file '%s': %w", filename, err)}
+		}
+		return fileDownloadedMsg{filename: filename}
+	})
+}
+
+// showArchiveFolder lists a folder of the archive being browsed
+func (m *Model) showArchiveFolder(path string) {
+	m.archivePath = path
+	m.objects, m.dirStatsCache = m.archive.list(path)
+	m.cursor = 0
+	m.scrollOffset = 0
+	m.selectedFiles = []string{}
+}
+
+// updateArchive handles the browser keys that behave differently inside an archive
+func (m Model) updateArchive(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "enter", "l", "o":
+		if len(m.objects) == 0 {
+			return m, nil
+		}
+		selected := m.objects[m.cursor]
+		if selected.IsDir {
+			m.showArchiveFolder(selected.Key)
+			return m, nil
+		}
+		if entry, ok := m.archive.entry(selected.Key); ok {
+			m.err = nil
+			return m, m.previewArchiveEntry(entry)
+		}
+
+	case "backspace", "h", "esc":
+		if m.archivePath != "" && msg.String() != "esc" {
+			m.showArchiveFolder(parentPrefix(m.archivePath))
+			return m, nil
+		}
+		// Leave the archive and go back to its folder
+		m.archive = nil
+		m.archivePath = ""
+		m.loading = true
+		m.dirStatsCache = make(map[string]DirStats)
+		m.selectedFiles = []string{}
+		return m, m.loadObjects()
+
+	case "d":
+		if len(m.objects) == 0 {
+			return m, nil
+		}
+		selected := m.objects[m.cursor]
+		if selected.IsDir {
+			m.err = fmt.Errorf("cannot extract folders, extract single entries instead")
+			return m, nil
+		}
+		m.confirmAction = "extract"
+		m.confirmTarget = selected.Key
+		m.viewMode = ViewConfirm
+		m.err = nil
+		m.statusMessage = ""
+
+	default:
+		m.err = fmt.Errorf("'%s' is not available inside an archive", msg.String())
+	}
+	return m, nil
+}
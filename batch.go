@@ -0,0 +1,214 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// batchConcurrency is the number of objects a batch job works on at once
+const batchConcurrency = 8
+
+// batchJob applies one operation to many objects in the background, reporting
+// progress through the same messages as transfers
+type batchJob struct {
+	title string
+	keys  []string
+	apply func(ctx context.Context, key string) error
+}
+
+// batchFailure is an object a batch job could not process
+type batchFailure struct {
+	key string
+	err error
+}
+
+type batchDoneMsg struct {
+	title    string
+	total    int
+	failures []batchFailure
+}
+
+// batchTargets returns the keys a bulk operation works on: the selection, or
+// the item under the cursor when nothing is selected
+func (m Model) batchTargets() []string {
+	if len(m.selectedFiles) > 0 {
+		return append([]string{}, m.selectedFiles...)
+	}
+	if len(m.objects) > 0 {
+		return []string{m.objects[m.cursor].Key}
+	}
+	return nil
+}
+
+// expandKeys replaces folder keys (ending in "/") with every object below
+// them. Folder marker objects are left out, as are duplicates.
+func expandKeys(ctx context.Context, client *S3Client, bucket string, keys []string) ([]string, error) {
+	var expanded []string
+	seen := make(map[string]bool)
+	for _, key := range keys {
+		if !strings.HasSuffix(key, "/") {
+			if !seen[key] {
+				seen[key] = true
+				expanded = append(expanded, key)
+			}
+			continue
+		}
+		objects, err := client.ListAllObjects(ctx, bucket, key)
+		if err != nil {
+			return nil, err
+		}
+		for _, obj := range objects {
+			if !strings.HasSuffix(obj.Key, "/") && !seen[obj.Key] {
+				seen[obj.Key] = true
+				expanded = append(expanded, obj.Key)
+			}
+		}
+	}
+	if len(expanded) == 0 {
+		return nil, fmt.Errorf("no objects to work on")
+	}
+	return expanded, nil
+}
+
+// startBatch runs a job in the background and returns a message carrying its update channel
+func startBatch(job batchJob) tea.Msg {
+	updates := make(chan tea.Msg, 16)
+	go runBatch(job, updates)
+	return transferStartedMsg{title: job.title, total: len(job.keys), updates: updates}
+}
+
+// runBatch applies the job's operation to every key with a few workers
+func runBatch(job batchJob, updates chan<- tea.Msg) {
+	defer close(updates)
+	ctx := context.Background()
+	total := len(job.keys)
+
+	var mu sync.Mutex
+	var failures []batchFailure
+	done := 0
+	keys := make(chan string)
+	var wg sync.WaitGroup
+	for w := 0; w < min(batchConcurrency, total); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for key := range keys {
+				err := job.apply(ctx, key)
+
+				mu.Lock()
+				if err != nil {
+					failures = append(failures, batchFailure{key: key, err: err})
+				}
+				done++
+				progress := transferProgressMsg{done: done, total: total, current: key}
+				mu.Unlock()
+				updates <- progress
+			}
+		}()
+	}
+	for _, key := range job.keys {
+		keys <- key
+	}
+	close(keys)
+	wg.Wait()
+
+	updates <- batchDoneMsg{title: job.title, total: total, failures: failures}
+}
+
+// applyBatchDone reports the result of a batch job. Single objects get a status
+// line; larger jobs and any failures get the report view.
+func (m Model) applyBatchDone(msg batchDoneMsg) (tea.Model, tea.Cmd) {
+	m.loading = false
+	m.progressUpdates = nil
+	m.viewMode = ViewBrowser
+
+	failed := len(msg.failures)
+	if msg.total == 1 && failed == 0 {
+		m.err = nil
+		m.statusMessage = fmt.Sprintf("✓ %s: done", msg.title)
+		return m, m.loadObjects()
+	}
+
+	m.reportTitle = msg.title
+	m.reportSummary = fmt.Sprintf("%d of %d object(s) done, %d failed", msg.total-failed, msg.total, failed)
+	m.reportLines = nil
+	for _, failure := range msg.failures {
+		m.reportLines = append(m.reportLines, fmt.Sprintf("%s: %v", displayKey(failure.key), failure.err))
+	}
+	m.reportScroll = 0
+	m.viewMode = ViewReport
+	m.err = nil
+	m.statusMessage = ""
+	return m, m.loadObjects()
+}
+
+// updateReport handles batch report view updates
+func (m Model) updateReport(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c", "q":
+		return m, tea.Quit
+	case "esc", "enter":
+		m.viewMode = ViewBrowser
+		m.reportLines = nil
+		m.statusMessage = fmt.Sprintf("%s: %s", m.reportTitle, m.reportSummary)
+	case "up", "k":
+		if m.reportScroll > 0 {
+			m.reportScroll--
+		}
+	case "down", "j":
+		if m.reportScroll < len(m.reportLines)-1 {
+			m.reportScroll++
+		}
+	case "y":
+		text := m.reportTitle + ": " + m.reportSummary + "\n" + strings.Join(m.reportLines, "\n")
+		return m, copyCmd("report", text)
+	}
+	return m, nil
+}
+
+// viewReport renders the result of a batch job
+func (m Model) viewReport() string {
+	var s strings.Builder
+
+	s.WriteString(titleStyle.Render(m.reportTitle))
+	s.WriteString("\n\n")
+	if len(m.reportLines) == 0 {
+		s.WriteString(successStyle.Render("✓ " + m.reportSummary))
+	} else {
+		s.WriteString(errorStyle.Render(m.reportSummary))
+	}
+	s.WriteString("\n\n")
+
+	height := max(m.height-16, 5)
+	width := max(m.width-20, 40)
+	var lines []string
+	for i := m.reportScroll; i < len(m.reportLines) && i < m.reportScroll+height; i++ {
+		lines = append(lines, truncateString(m.reportLines[i], width))
+	}
+	if len(lines) > 0 {
+		s.WriteString(lipgloss.NewStyle().Align(lipgloss.Left).Render(strings.Join(lines, "\n")))
+		s.WriteString("\n\n")
+	}
+	if m.statusMessage != "" {
+		s.WriteString(helpStyle.Render(m.statusMessage) + "\n\n")
+	}
+	s.WriteString(helpStyle.Render("↑/k,↓/j: scroll • y: copy report • enter/esc: close"))
+
+	popupStyle := lipgloss.NewStyle().
+		Border(lipgloss.DoubleBorder()).
+		BorderForeground(lipgloss.Color("#0066cc")).
+		Padding(2, 4).
+		Align(lipgloss.Center)
+
+	popup := popupStyle.Render(s.String())
+	if m.width > 0 && m.height > 0 {
+		centered := centerStyle.Width(m.width).Render(popup)
+		return verticalCenterStyle.Height(m.height).Render(centered)
+	}
+	return popup
+}
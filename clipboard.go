@@ -0,0 +1,76 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+
+	"github.com/aymanbagabas/go-osc52/v2"
+)
+
+// terminalOutput is the output of the program. Writes are serialized, so an
+// escape sequence written outside the renderer, like OSC 52, is never
+// interleaved with a frame. It is a term.File, which keeps bubbletea's
+// terminal size and raw mode handling working.
+type terminalOutput struct {
+	mu   sync.Mutex
+	file *os.File
+}
+
+func (t *terminalOutput) Write(p []byte) (int, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.file.Write(p)
+}
+
+func (t *terminalOutput) Read(p []byte) (int, error) { return t.file.Read(p) }
+func (t *terminalOutput) Close() error               { return t.file.Close() }
+func (t *terminalOutput) Fd() uintptr                { return t.file.Fd() }
+
+// terminal is the output the TUI renders to
+var terminal = &terminalOutput{file: os.Stdout}
+
+// clipboardCommands are the programs tried, in order, to set the clipboard
+var clipboardCommands = [][]string{
+	{"pbcopy"},
+	{"wl-copy"},
+	{"xclip", "-selection", "clipboard"},
+	{"xsel", "--clipboard", "--input"},
+}
+
+// copyToClipboard puts text on the system clipboard. Local clipboard programs
+// are tried first; without one (over SSH, say) the terminal is asked to set
+// the clipboard with an OSC 52 escape sequence.
+func copyToClipboard(text string) error {
+	for _, args := range clipboardCommands {
+		if _, err := exec.LookPath(args[0]); err != nil {
+			continue
+		}
+		cmd := exec.Command(args[0], args[1:]...)
+		cmd.Stdin = strings.NewReader(text)
+		if err := cmd.Run(); err == nil {
+			return nil
+		}
+	}
+
+	if _, err := osc52Sequence(text).WriteTo(terminal); err != nil {
+		return fmt.Errorf("failed to copy to clipboard: %w", err)
+	}
+	return nil
+}
+
+// osc52Sequence returns the OSC 52 sequence that sets the clipboard to text,
+// wrapped for tmux or screen when running inside one, as they do not pass
+// the plain sequence on to the terminal
+func osc52Sequence(text string) osc52.Sequence {
+	seq := osc52.New(text)
+	switch {
+	case os.Getenv("TMUX") != "":
+		seq = seq.Tmux()
+	case os.Getenv("STY") != "":
+		seq = seq.Screen()
+	}
+	return seq
+}
@@ -0,0 +1,377 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/hamba/avro/v2/ocf"
+	"github.com/parquet-go/parquet-go"
+	"github.com/parquet-go/parquet-go/format"
+)
+
+const (
+	// columnarPreviewRows is the number of rows read for the table of a Parquet or Avro file
+	columnarPreviewRows = 100
+	// avroMaxBlocks limits how many Avro blocks are visited to gather statistics
+	avroMaxBlocks = 200
+	// columnarMaxStat is the longest min/max statistic shown
+	columnarMaxStat = 24
+)
+
+// Columnar formats with a schema and row preview
+const (
+	formatParquet = "parquet"
+	formatAvro    = "avro"
+)
+
+// columnarPreview is the summary and first rows of a Parquet or Avro file
+type columnarPreview struct {
+	summary string
+	columns []string
+	rows    [][]string
+}
+
+// columnarFormat detects Parquet and Avro files from the extension or leading bytes
+func columnarFormat(key string, head []byte) string {
+	switch strings.ToLower(filepath.Ext(keyName(key))) {
+	case ".parquet", ".parq":
+		return formatParquet
+	case ".avro":
+		return formatAvro
+	}
+	switch {
+	case bytes.HasPrefix(head, []byte("PAR1")):
+		return formatParquet
+	case bytes.HasPrefix(head, []byte("Obj\x01")):
+		return formatAvro
+	}
+	return ""
+}
+
+// readColumnar reads the schema, statistics and first rows of a columnar file
+// through ranged reads
+func readColumnar(reader io.ReaderAt, size int64, kind string) (*columnarPreview, error) {
+	switch kind {
+	case formatParquet:
+		return readParquet(reader, size)
+	case formatAvro:
+		return readAvro(reader, size)
+	}
+	return nil, fmt.Errorf("unsupported format '%s'", kind)
+}
+
+// readParquet reads the footer of a Parquet file for the schema and row group
+// statistics, then the pages of the first row groups for the first rows
+func readParquet(reader io.ReaderAt, size int64) (*columnarPreview, error) {
+	file, err := parquet.OpenFile(reader, size, parquet.SkipPageIndex(true), parquet.SkipBloomFilters(true))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read Parquet footer: %w", err)
+	}
+	metadata := file.Metadata()
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Parquet file: %d rows in %d row group(s), %s\n", file.NumRows(), len(metadata.RowGroups), formatSize(size))
+	if metadata.CreatedBy != "" {
+		fmt.Fprintf(&b, "Created by: %s\n", metadata.CreatedBy)
+	}
+	b.WriteString("\nSchema:\n")
+	b.WriteString(strings.ReplaceAll(file.Schema().String(), "\t", "  "))
+	b.WriteString("\n")
+
+	for i, rowGroup := range metadata.RowGroups {
+		var compressed int64
+		for _, chunk := range rowGroup.Columns {
+			compressed += chunk.MetaData.TotalCompressedSize
+		}
+		fmt.Fprintf(&b, "\nRow group %d: %d rows, %s compressed, %s uncompressed\n",
+			i+1, rowGroup.NumRows, formatSize(compressed), formatSize(rowGroup.TotalByteSize))
+
+		table := tabwriter.NewWriter(&b, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(table, "  column\tcodec\tcompressed\tuncompressed\tnulls\tmin\tmax")
+		for _, chunk := range rowGroup.Columns {
+			meta := chunk.MetaData
+			minValue, maxValue := parquetStats(file.Schema(), meta)
+			fmt.Fprintf(table, "  %s\t%s\t%s\t%s\t%d\t%s\t%s\n",
+				strings.Join(meta.PathInSchema, "."), meta.Codec, formatSize(meta.TotalCompressedSize),
+				formatSize(meta.TotalUncompressedSize), meta.Statistics.NullCount, minValue, maxValue)
+		}
+		table.Flush()
+	}
+
+	preview := &columnarPreview{summary: b.String()}
+	for _, path := range file.Schema().Columns() {
+		preview.columns = append(preview.columns, strings.Join(path, "."))
+	}
+
+	// Rows are read from the first row groups until enough have been collected
+	buffer := make([]parquet.Row, 16)
+	for _, rowGroup := range file.RowGroups() {
+		rows := rowGroup.Rows()
+		for len(preview.rows) < columnarPreviewRows {
+			n, err := rows.ReadRows(buffer[:min(len(buffer), columnarPreviewRows-len(preview.rows))])
+			for _, row := range buffer[:n] {
+				preview.rows = append(preview.rows, parquetRowCells(row, len(preview.columns)))
+			}
+			if errors.Is(err, io.EOF) || n == 0 {
+				break
+			}
+			if err != nil {
+				rows.Close()
+				return nil, fmt.Errorf("failed to read Parquet rows: %w", err)
+			}
+		}
+		rows.Close()
+		if len(preview.rows) >= columnarPreviewRows {
+			break
+		}
+	}
+	return preview, nil
+}
+
+// parquetStats decodes the min and max statistics of a column chunk
+func parquetStats(schema *parquet.Schema, meta format.ColumnMetaData) (string, string) {
+	leaf, ok := schema.Lookup(meta.PathInSchema...)
+	if !ok {
+		return "", ""
+	}
+	minBytes, maxBytes := meta.Statistics.MinValue, meta.Statistics.MaxValue
+	if minBytes == nil && maxBytes == nil {
+		// Older writers only fill the deprecated fields
+		minBytes, maxBytes = meta.Statistics.Min, meta.Statistics.Max
+	}
+
+	render := func(data []byte) (text string) {
+		if data == nil {
+			return "-"
+		}
+		// Kind.Value panics on statistics of the wrong length
+		defer func() {
+			if recover() != nil {
+				text = "?"
+			}
+		}()
+		return truncateString(displayKey(leaf.Node.Type().Kind().Value(data).String()), columnarMaxStat)
+	}
+	return render(minBytes), render(maxBytes)
+}
+
+// parquetRowCells turns a row into one cell per leaf column; the values of
+// repeated columns are joined with commas
+func parquetRowCells(row parquet.Row, columns int) []string {
+	cells := make([]string, columns)
+	for _, value := range row {
+		column := value.Column()
+		if column < 0 || column >= columns {
+			continue
+		}
+		text := value.String()
+		if value.IsNull() {
+			text = "null"
+		}
+		if cells[column] != "" {
+			text = cells[column] + "," + text
+		}
+		cells[column] = text
+	}
+	return cells
+}
+
+// byteCursor reads bytes one at a time from a position of a ReaderAt
+type byteCursor struct {
+	reader io.ReaderAt
+	pos    int64
+}
+
+// ReadByte implements io.ByteReader
+func (c *byteCursor) ReadByte() (byte, error) {
+	var b [1]byte
+	if _, err := c.reader.ReadAt(b[:], c.pos); err != nil {
+		return 0, err
+	}
+	c.pos++
+	return b[0], nil
+}
+
+// readLong reads a zig-zag encoded Avro long
+func (c *byteCursor) readLong() (int64, error) {
+	value, err := binary.ReadUvarint(c)
+	if err != nil {
+		return 0, err
+	}
+	return int64(value>>1) ^ -int64(value&1), nil
+}
+
+// readBytes reads a length-prefixed Avro bytes or string value
+func (c *byteCursor) readBytes() ([]byte, error) {
+	length, err := c.readLong()
+	if err != nil {
+		return nil, err
+	}
+	if length < 0 || length > 16*1024*1024 {
+		return nil, fmt.Errorf("invalid length %d", length)
+	}
+	data := make([]byte, length)
+	if _, err := c.reader.ReadAt(data, c.pos); err != nil {
+		return nil, err
+	}
+	c.pos += length
+	return data, nil
+}
+
+// readAvro reads the header of an Avro object container file for the schema
+// and codec, hops from block header to block header for statistics, and
+// decodes the first records
+func readAvro(reader io.ReaderAt, size int64) (*columnarPreview, error) {
+	cursor := &byteCursor{reader: reader}
+	magic := make([]byte, 4)
+	if _, err := reader.ReadAt(magic, 0); err != nil || string(magic) != "Obj\x01" {
+		return nil, fmt.Errorf("not an Avro object container file")
+	}
+	cursor.pos = 4
+
+	// File metadata is an Avro map of bytes
+	metadata := make(map[string][]byte)
+	for {
+		count, err := cursor.readLong()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read Avro header: %w", err)
+		}
+		if count == 0 {
+			break
+		}
+		if count < 0 {
+			count = -count
+			if _, err := cursor.readLong(); err != nil { // Block size in bytes
+				return nil, fmt.Errorf("failed to read Avro header: %w", err)
+			}
+		}
+		for i := int64(0); i < count; i++ {
+			key, err := cursor.readBytes()
+			if err != nil {
+				return nil, fmt.Errorf("failed to read Avro header: %w", err)
+			}
+			value, err := cursor.readBytes()
+			if err != nil {
+				return nil, fmt.Errorf("failed to read Avro header: %w", err)
+			}
+			metadata[string(key)] = value
+		}
+	}
+	cursor.pos += 16 // Sync marker
+
+	// Each block starts with its record count and byte size, so the data can be skipped
+	var blocks, records, blockBytes int64
+	complete := true
+	for cursor.pos < size {
+		if blocks == avroMaxBlocks {
+			complete = false
+			break
+		}
+		count, err := cursor.readLong()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read Avro block %d: %w", blocks+1, err)
+		}
+		length, err := cursor.readLong()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read Avro block %d: %w", blocks+1, err)
+		}
+		cursor.pos += length + 16
+		blocks++
+		records += count
+		blockBytes += length
+	}
+
+	codec := string(metadata["avro.codec"])
+	if codec == "" {
+		codec = "null"
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Avro object container file, %s\n", formatSize(size))
+	fmt.Fprintf(&b, "Codec: %s\n", codec)
+	if complete {
+		fmt.Fprintf(&b, "Blocks: %d, %d records", blocks, records)
+	} else {
+		fmt.Fprintf(&b, "Blocks: first %d of more, %d records in them", blocks, records)
+	}
+	if blocks > 0 {
+		fmt.Fprintf(&b, ", %s per block on average", formatSize(blockBytes/blocks))
+	}
+	b.WriteString("\n\nSchema:\n")
+	var schema bytes.Buffer
+	if err := json.Indent(&schema, metadata["avro.schema"], "", "  "); err != nil {
+		schema.Write(metadata["avro.schema"])
+	}
+	b.WriteString(schema.String())
+	b.WriteString("\n")
+	var keys []string
+	for key := range metadata {
+		if !strings.HasPrefix(key, "avro.") {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		fmt.Fprintf(&b, "\nMetadata %s: %s", displayKey(key), displayKey(string(metadata[key])))
+	}
+
+	preview := &columnarPreview{summary: b.String()}
+
+	// Records are decoded from the start of the file until enough have been read
+	decoder, err := ocf.NewDecoder(io.NewSectionReader(reader, 0, size))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read Avro records: %w", err)
+	}
+	var decoded []map[string]string
+	for len(decoded) < columnarPreviewRows && decoder.HasNext() {
+		var record interface{}
+		if err := decoder.Decode(&record); err != nil {
+			return nil, fmt.Errorf("failed to decode Avro record: %w", err)
+		}
+		fields := make(map[string]string)
+		if _, ok := record.(map[string]interface{}); ok {
+			flattenJSON("", record, fields)
+		} else {
+			fields["value"] = fmt.Sprint(record)
+		}
+		decoded = append(decoded, fields)
+	}
+	if err := decoder.Error(); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("failed to read Avro records: %w", err)
+	}
+	preview.columns, preview.rows = recordTable(decoded)
+	return preview, nil
+}
+
+// recordTable lays out flattened records as rows, with the columns sorted by name
+func recordTable(records []map[string]string) ([]string, [][]string) {
+	seen := make(map[string]bool)
+	var columns []string
+	for _, record := range records {
+		for name := range record {
+			if !seen[name] {
+				seen[name] = true
+				columns = append(columns, name)
+			}
+		}
+	}
+	sort.Strings(columns)
+
+	rows := make([][]string, len(records))
+	for i, record := range records {
+		row := make([]string, len(columns))
+		for j, name := range columns {
+			row[j] = record[name]
+		}
+		rows[i] = row
+	}
+	return columns, rows
+}
@@ -23,6 +23,11 @@ type S3Config struct {
 
 // LoadS3Config loads configuration from .s3cfg file
 func LoadS3Config() (*S3Config, error) {
+	return LoadS3ConfigProfile("default")
+}
+
+// LoadS3ConfigProfile loads configuration from the named section of .s3cfg
+func LoadS3ConfigProfile(profile string) (*S3Config, error) {
 	// Try to find .s3cfg in common locations
 	configPaths := []string{
 		".s3cfg",
@@ -47,7 +52,10 @@ func LoadS3Config() (*S3Config, error) {
 		return nil, fmt.Errorf("failed to load .s3cfg: %w", err)
 	}
 
-	section := cfg.Section("default")
+	if !cfg.HasSection(profile) {
+		return nil, fmt.Errorf("profile '%s' not found in %s", profile, configPath)
+	}
+	section := cfg.Section(profile)
 	
 	config := &S3Config{
 		AccessKey:   section.Key("access_key").String(),
@@ -0,0 +1,307 @@
+package main
+
+import (
+	"bytes"
+	"compress/bzip2"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/ulikunitz/xz"
+)
+
+// Compression formats that the preview decompresses on the fly
+const (
+	codecGzip  = "gzip"
+	codecZstd  = "zstd"
+	codecBzip2 = "bzip2"
+	codecXz    = "xz"
+)
+
+// compressionFromName detects a compression format from the key's extension
+// or the Content-Encoding header, returning "" if neither names one
+func compressionFromName(key, contentEncoding string) string {
+	switch strings.ToLower(filepath.Ext(keyName(key))) {
+	case ".gz", ".gzip", ".tgz":
+		return codecGzip
+	case ".zst", ".zstd", ".tzst":
+		return codecZstd
+	case ".bz2", ".tbz2":
+		return codecBzip2
+	case ".xz", ".txz":
+		return codecXz
+	}
+
+	// Content-Encoding may list several codings; the last one was applied last
+	encodings := strings.Split(contentEncoding, ",")
+	switch strings.ToLower(strings.TrimSpace(encodings[len(encodings)-1])) {
+	case "gzip", "x-gzip":
+		return codecGzip
+	case "zstd":
+		return codecZstd
+	case "bzip2", "x-bzip2":
+		return codecBzip2
+	case "xz", "x-xz":
+		return codecXz
+	}
+	return ""
+}
+
+// compressionFromMagic detects a compression format from the leading bytes of an object
+func compressionFromMagic(head []byte) string {
+	switch {
+	case bytes.HasPrefix(head, []byte{0x1f, 0x8b}):
+		return codecGzip
+	case bytes.HasPrefix(head, []byte{0x28, 0xb5, 0x2f, 0xfd}):
+		return codecZstd
+	case len(head) >= 4 && bytes.HasPrefix(head, []byte("BZh")) && head[3] >= '1' && head[3] <= '9':
+		return codecBzip2
+	case bytes.HasPrefix(head, []byte{0xfd, '7', 'z', 'X', 'Z', 0x00}):
+		return codecXz
+	}
+	return ""
+}
+
+// decompressReader wraps body in a reader for the given compression format.
+// The returned function releases the decompressor and closes body.
+func decompressReader(body io.ReadCloser, codec string) (io.Reader, func(), error) {
+	var reader io.Reader
+	closeReader := func() {}
+	var err error
+	switch codec {
+	case codecGzip:
+		gz, gzErr := gzip.NewReader(body)
+		if gzErr != nil {
+			err = gzErr
+			break
+		}
+		reader = gz
+		closeReader = func() { gz.Close() }
+	case codecZstd:
+		decoder, zstdErr := zstd.NewReader(body, zstd.WithDecoderConcurrency(1))
+		if zstdErr != nil {
+			err = zstdErr
+			break
+		}
+		reader = decoder
+		closeReader = decoder.Close
+	case codecBzip2:
+		reader = bzip2.NewReader(body)
+	case codecXz:
+		reader, err = xz.NewReader(body)
+	default:
+		err = fmt.Errorf("unsupported compression '%s'", codec)
+	}
+	if err != nil {
+		body.Close()
+		return nil, nil, fmt.Errorf("failed to decompress %s: %w", codec, err)
+	}
+
+	return reader, func() {
+		closeReader()
+		body.Close()
+	}, nil
+}
+
+// openFunc starts reading a stream from the beginning. The returned function
+// stops reading and releases everything that was opened.
+type openFunc func(ctx context.Context) (io.Reader, func(), error)
+
+// decompressStream reads a compressed object (or an archive entry) as a stream
+// of decompressed bytes. Decompressed offsets cannot be read with a ranged GET,
+// so the stream is kept open between reads: reading on from the current
+// position is cheap, while going back restarts the download and skips forward.
+type decompressStream struct {
+	mu     sync.Mutex
+	open   openFunc
+	label  string          // Names the format in errors
+	ctx    context.Context // Downloads are opened with it; Close cancels it
+	cancel context.CancelFunc
+
+	reader io.Reader
+	close  func()
+	pos    int64 // Decompressed offset of the next byte read
+	closed bool
+}
+
+// newStream creates a stream read through open; nothing is read until the first range
+func newStream(label string, open openFunc) *decompressStream {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &decompressStream{label: label, open: open, ctx: ctx, cancel: cancel}
+}
+
+// newDecompressStream creates a stream for an object, or a version of it
+func newDecompressStream(client *S3Client, bucket, key, versionID, codec string) *decompressStream {
+	return newStream(codec, func(ctx context.Context) (io.Reader, func(), error) {
+		body, err := client.GetObjectVersionStream(ctx, bucket, key, versionID)
+		if err != nil {
+			return nil, nil, err
+		}
+		return decompressReader(body, codec)
+	})
+}
+
+// contextReader stops reading once its context is cancelled, so that long
+// skips and tail reads end soon after the preview is closed
+type contextReader struct {
+	ctx    context.Context
+	reader io.Reader
+}
+
+func (r contextReader) Read(p []byte) (int, error) {
+	if err := r.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return r.reader.Read(p)
+}
+
+// readContext returns a context cancelled when ctx is or when the stream is closed
+func (s *decompressStream) readContext(ctx context.Context) (context.Context, func()) {
+	ctx, cancel := context.WithCancel(ctx)
+	stop := context.AfterFunc(s.ctx, cancel)
+	return ctx, func() {
+		stop()
+		cancel()
+	}
+}
+
+// restart starts reading the stream from the beginning. The download is
+// opened with the stream's context, as it outlives the read that started it.
+func (s *decompressStream) restart() error {
+	s.release()
+
+	reader, closeReader, err := s.open(s.ctx)
+	if err != nil {
+		return err
+	}
+	s.reader = reader
+	s.close = closeReader
+	s.pos = 0
+	return nil
+}
+
+// release closes the current download, if any
+func (s *decompressStream) release() {
+	if s.reader == nil {
+		return
+	}
+	s.close()
+	s.reader = nil
+}
+
+// ReadRange returns the decompressed bytes [start, end). eof is true when the
+// end of the decompressed data was reached, which makes the total size known.
+func (s *decompressStream) ReadRange(ctx context.Context, start, end int64) ([]byte, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return nil, false, fmt.Errorf("preview closed")
+	}
+	if s.reader == nil || start < s.pos {
+		if err := s.restart(); err != nil {
+			return nil, false, err
+		}
+	}
+	ctx, stop := s.readContext(ctx)
+	defer stop()
+	reader := contextReader{ctx: ctx, reader: s.reader}
+
+	if skip := start - s.pos; skip > 0 {
+		skipped, err := io.CopyN(io.Discard, reader, skip)
+		s.pos += skipped
+		if errors.Is(err, io.EOF) {
+			s.release()
+			return nil, true, nil
+		}
+		if err != nil {
+			s.release()
+			return nil, false, fmt.Errorf("failed to read %s: %w", s.label, err)
+		}
+	}
+
+	data := make([]byte, end-start)
+	n, err := io.ReadFull(reader, data)
+	s.pos += int64(n)
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		s.release()
+		return data[:n], true, nil
+	}
+	if err != nil {
+		s.release()
+		return nil, false, fmt.Errorf("failed to read %s: %w", s.label, err)
+	}
+	return data, false, nil
+}
+
+// ReadTail decompresses the rest of the object and returns its last n bytes
+// together with their decompressed offset
+func (s *decompressStream) ReadTail(ctx context.Context, n int64) ([]byte, int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return nil, 0, fmt.Errorf("preview closed")
+	}
+	if s.reader == nil {
+		if err := s.restart(); err != nil {
+			return nil, 0, err
+		}
+	}
+	ctx, stop := s.readContext(ctx)
+	defer stop()
+
+	// Keep the last n bytes read so far
+	tail := make([]byte, 0, 2*n)
+	buf := make([]byte, 64*1024)
+	for {
+		if err := ctx.Err(); err != nil {
+			s.release()
+			return nil, 0, fmt.Errorf("failed to read %s: %w", s.label, err)
+		}
+		read, err := s.reader.Read(buf)
+		s.pos += int64(read)
+		tail = append(tail, buf[:read]...)
+		if int64(len(tail)) > n {
+			tail = append(tail[:0], tail[int64(len(tail))-n:]...)
+		}
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			s.release()
+			return nil, 0, fmt.Errorf("failed to read %s: %w", s.label, err)
+		}
+	}
+	s.release()
+	return tail, s.pos - int64(len(tail)), nil
+}
+
+// Close stops the download; later reads fail. A read in progress is cancelled
+// first, so Close does not wait for it to decompress the rest of the object.
+func (s *decompressStream) Close() {
+	s.cancel()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.release()
+	s.closed = true
+}
+
+// decompressedName strips the compression extension from a key, so that
+// "app.log.gz" is highlighted as "app.log" and "data.tgz" becomes "data.tar"
+func decompressedName(key string) string {
+	ext := filepath.Ext(keyName(key))
+	switch strings.ToLower(ext) {
+	case ".gz", ".gzip", ".zst", ".zstd", ".bz2", ".xz":
+		return strings.TrimSuffix(key, ext)
+	case ".tgz", ".tzst", ".tbz2", ".txz":
+		return strings.TrimSuffix(key, ext) + ".tar"
+	}
+	return key
+}
@@ -0,0 +1,94 @@
+package main
+
+import "testing"
+
+func TestCompressionFromName(t *testing.T) {
+	tests := []struct {
+		key             string
+		contentEncoding string
+		want            string
+	}{
+		{"logs/app.log.gz", "", codecGzip},
+		{"APP.LOG.GZ", "", codecGzip},
+		{"data.gzip", "", codecGzip},
+		{"data.tgz", "", codecGzip},
+		{"data.zst", "", codecZstd},
+		{"data.zstd", "", codecZstd},
+		{"data.tzst", "", codecZstd},
+		{"data.bz2", "", codecBzip2},
+		{"data.tbz2", "", codecBzip2},
+		{"data.xz", "", codecXz},
+		{"data.txz", "", codecXz},
+		{"plain.txt", "", ""},
+		{"dir.gz/file.txt", "", ""},
+		{"data.xz", "gzip", codecXz},
+		{"data.json", "gzip", codecGzip},
+		{"data.json", "x-gzip", codecGzip},
+		{"data.json", " GZIP ", codecGzip},
+		{"data.json", "zstd", codecZstd},
+		{"data.json", "x-bzip2", codecBzip2},
+		{"data.json", "x-xz", codecXz},
+		{"data.json", "br, gzip", codecGzip},
+		{"data.json", "gzip, br", ""},
+		{"data.json", "deflate,zstd", codecZstd},
+		{"data.json", "identity", ""},
+		{"data.json", "", ""},
+	}
+	for _, tt := range tests {
+		if got := compressionFromName(tt.key, tt.contentEncoding); got != tt.want {
+			t.Errorf("compressionFromName(%q, %q) = %q, want %q", tt.key, tt.contentEncoding, got, tt.want)
+		}
+	}
+}
+
+func TestCompressionFromMagic(t *testing.T) {
+	tests := []struct {
+		head string
+		want string
+	}{
+		{"\x1f\x8b\x08\x00", codecGzip},
+		{"\x28\xb5\x2f\xfd\x04", codecZstd},
+		{"BZh1", codecBzip2},
+		{"BZh9\x31\x41", codecBzip2},
+		{"BZh0", ""},
+		{"BZh:", ""},
+		{"BZh", ""},
+		{"\xfd7zXZ\x00\x00", codecXz},
+		{"\xfd7zXZ", ""},
+		{"\x1f", ""},
+		{"PK\x03\x04", ""},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := compressionFromMagic([]byte(tt.head)); got != tt.want {
+			t.Errorf("compressionFromMagic(%q) = %q, want %q", tt.head, got, tt.want)
+		}
+	}
+}
+
+func TestDecompressedName(t *testing.T) {
+	tests := []struct {
+		key  string
+		want string
+	}{
+		{"app.log.gz", "app.log"},
+		{"logs/app.log.GZ", "logs/app.log"},
+		{"data.gzip", "data"},
+		{"data.json.zst", "data.json"},
+		{"data.zstd", "data"},
+		{"data.bz2", "data"},
+		{"backup.tar.xz", "backup.tar"},
+		{"backup.tgz", "backup.tar"},
+		{"backup.TXZ", "backup.tar"},
+		{"backup.tzst", "backup.tar"},
+		{"backup.tbz2", "backup.tar"},
+		{"plain.txt", "plain.txt"},
+		{"dir.gz/file", "dir.gz/file"},
+		{"noext", "noext"},
+	}
+	for _, tt := range tests {
+		if got := decompressedName(tt.key); got != tt.want {
+			t.Errorf("decompressedName(%q) = %q, want %q", tt.key, got, tt.want)
+		}
+	}
+}
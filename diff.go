@@ -0,0 +1,171 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+const (
+	// diffContext is the number of unchanged lines shown around each change
+	diffContext = 3
+	// diffMaxCells bounds the line comparison table; larger changes are shown
+	// as a whole block removed and added
+	diffMaxCells = 4 * 1024 * 1024
+)
+
+var (
+	diffAddStyle    = lipgloss.NewStyle().Foreground(lipgloss.Color("#00aa00"))
+	diffRemoveStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#cc0000"))
+	diffHunkStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("#00aaaa"))
+)
+
+// diffOp is one line of a diff: ' ' kept, '-' removed or '+' added
+type diffOp struct {
+	kind byte
+	text string
+}
+
+// diffLines compares two texts line by line and returns the edit script
+func diffLines(before, after string) []diffOp {
+	a := splitLines(before)
+	b := splitLines(after)
+
+	// Lines shared at both ends need no comparison table
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	var ops []diffOp
+	for _, line := range a[:prefix] {
+		ops = append(ops, diffOp{' ', line})
+	}
+	ops = append(ops, diffMiddle(a[prefix:len(a)-suffix], b[prefix:len(b)-suffix])...)
+	for _, line := range a[len(a)-suffix:] {
+		ops = append(ops, diffOp{' ', line})
+	}
+	return ops
+}
+
+// diffMiddle diffs the changed middle of two texts through their longest common subsequence
+func diffMiddle(a, b []string) []diffOp {
+	var ops []diffOp
+	if len(a)*len(b) > diffMaxCells {
+		for _, line := range a {
+			ops = append(ops, diffOp{'-', line})
+		}
+		for _, line := range b {
+			ops = append(ops, diffOp{'+', line})
+		}
+		return ops
+	}
+
+	// lcs[i][j] is the common subsequence length of a[i:] and b[j:]
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			ops = append(ops, diffOp{' ', a[i]})
+			i++
+			j++
+		case i < len(a) && (j == len(b) || lcs[i+1][j] >= lcs[i][j+1]):
+			// Removals come before additions, as in diff -u
+			ops = append(ops, diffOp{'-', a[i]})
+			i++
+		default:
+			ops = append(ops, diffOp{'+', b[j]})
+			j++
+		}
+	}
+	return ops
+}
+
+// splitLines splits text into lines without their line endings
+func splitLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	lines := strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimSuffix(line, "\r")
+	}
+	return lines
+}
+
+// formatDiff renders an edit script as unified diff hunks with coloured lines
+func formatDiff(ops []diffOp) []string {
+	// Mark the lines that are changes or close enough to one to be shown
+	show := make([]bool, len(ops))
+	for i, op := range ops {
+		if op.kind == ' ' {
+			continue
+		}
+		for j := max(i-diffContext, 0); j <= min(i+diffContext, len(ops)-1); j++ {
+			show[j] = true
+		}
+	}
+
+	var lines []string
+	oldLine, newLine := 1, 1
+	for i := 0; i < len(ops); {
+		if !show[i] {
+			if ops[i].kind != '+' {
+				oldLine++
+			}
+			if ops[i].kind != '-' {
+				newLine++
+			}
+			i++
+			continue
+		}
+
+		// A hunk runs until the next line that is not shown
+		end := i
+		oldCount, newCount := 0, 0
+		for end < len(ops) && show[end] {
+			if ops[end].kind != '+' {
+				oldCount++
+			}
+			if ops[end].kind != '-' {
+				newCount++
+			}
+			end++
+		}
+		lines = append(lines, diffHunkStyle.Render(fmt.Sprintf("@@ -%d,%d +%d,%d @@", oldLine, oldCount, newLine, newCount)))
+		for _, op := range ops[i:end] {
+			text := displayKey(expandTabs(op.text))
+			switch op.kind {
+			case '+':
+				lines = append(lines, diffAddStyle.Render("+"+text))
+			case '-':
+				lines = append(lines, diffRemoveStyle.Render("-"+text))
+			default:
+				lines = append(lines, " "+text)
+			}
+		}
+		oldLine += oldCount
+		newLine += newCount
+		i = end
+	}
+	return lines
+}
@@ -0,0 +1,195 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+const (
+	// editMaxSize is the largest object that can be opened in the editor
+	editMaxSize = 8 * 1024 * 1024
+	// editDiffLines is the number of diff lines shown when confirming an upload
+	editDiffLines = 20
+)
+
+// editSession is an object being edited in $EDITOR through a temporary file
+type editSession struct {
+	key      string
+	path     string            // Temporary file handed to the editor
+	info     *ObjectInfo       // Headers and ETag of the object when it was downloaded
+	tags     map[string]string // Tags of the object, written back with the edits
+	original []byte
+	edited   []byte
+	diff     []string // Rendered diff of the edits
+}
+
+// Messages for editing objects
+type editDownloadedMsg struct {
+	session *editSession
+	err     error
+}
+
+type editorClosedMsg struct {
+	session *editSession
+	err     error
+}
+
+type fileEditedMsg struct {
+	session *editSession
+	err     error
+}
+
+// editorCommand returns the command that edits path, from $VISUAL or $EDITOR
+func editorCommand(path string) *exec.Cmd {
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	// Editors are often configured with arguments, like "code --wait"
+	fields := strings.Fields(editor)
+	if len(fields) == 0 {
+		fields = []string{"vi"}
+	}
+	return exec.Command(fields[0], append(fields[1:], path)...)
+}
+
+// editObject downloads an object to a temporary file for editing. The ETag is
+// read first, so the upload fails if the object changes in the meantime.
+func (m Model) editObject(key string) tea.Cmd {
+	return tea.Cmd(func() tea.Msg {
+		info, err := m.s3Client.HeadObject(context.Background(), m.bucket, key)
+		if err != nil {
+			return editDownloadedMsg{err: err}
+		}
+		if info.Size > editMaxSize {
+			return editDownloadedMsg{err: fmt.Errorf("'%s' is too large to edit (over %s)", displayKey(keyName(key)), formatSize(editMaxSize))}
+		}
+		if info.SSECustomerAlgorithm != "" {
+			return editDownloadedMsg{err: fmt.Errorf("'%s' is encrypted with a customer key (SSE-C) and cannot be edited", displayKey(keyName(key)))}
+		}
+
+		// A plain upload would drop the tags, so they are written back with the
+		// edits. Servers without tagging support have none to keep.
+		tags, err := m.s3Client.GetObjectTagging(context.Background(), m.bucket, key)
+		if err != nil && !unsupportedRequest(err) {
+			return editDownloadedMsg{err: err}
+		}
+
+		data, err := m.s3Client.GetObject(context.Background(), m.bucket, key)
+		if err != nil {
+			return editDownloadedMsg{err: err}
+		}
+		if !utf8.Valid(data) {
+			return editDownloadedMsg{err: fmt.Errorf("'%s' is not a text file", displayKey(keyName(key)))}
+		}
+
+		// Keep the extension so the editor picks the right syntax
+		ext := strings.ReplaceAll(filepath.Ext(keyName(key)), "*", "")
+		file, err := os.CreateTemp("", "s4-edit-*"+ext)
+		if err != nil {
+			return editDownloadedMsg{err: fmt.Errorf("failed to create temporary file: %w", err)}
+		}
+		defer file.Close()
+		if _, err := file.Write(data); err != nil {
+			os.Remove(file.Name())
+			return editDownloadedMsg{err: fmt.Errorf("failed to write temporary file: %w", err)}
+		}
+
+		return editDownloadedMsg{session: &editSession{key: key, path: file.Name(), info: info, tags: tags, original: data}}
+	})
+}
+
+// runEditor suspends the TUI while the editor runs on the downloaded object
+func runEditor(session *editSession) tea.Cmd {
+	cmd := editorCommand(session.path)
+	// The editor needs the terminal itself, not the program's output wrapper
+	cmd.Stdout = os.Stdout
+	return tea.ExecProcess(cmd, func(err error) tea.Msg {
+		return editorClosedMsg{session: session, err: err}
+	})
+}
+
+// applyEditorClosed reads back the edited file and asks to upload the changes
+func (m Model) applyEditorClosed(msg editorClosedMsg) (tea.Model, tea.Cmd) {
+	session := msg.session
+	if msg.err != nil {
+		os.Remove(session.path)
+		m.err = fmt.Errorf("failed to run editor: %w", msg.err)
+		return m, nil
+	}
+
+	edited, err := os.ReadFile(session.path)
+	if err != nil {
+		os.Remove(session.path)
+		m.err = fmt.Errorf("failed to read edited file: %w", err)
+		return m, nil
+	}
+	if bytes.Equal(edited, session.original) {
+		os.Remove(session.path)
+		m.err = nil
+		m.statusMessage = fmt.Sprintf("No changes to '%s'", displayKey(keyName(session.key)))
+		return m, nil
+	}
+
+	session.edited = edited
+	session.diff = formatDiff(diffLines(string(session.original), string(edited)))
+	m.confirmAction = "edit_upload"
+	m.confirmTarget = session.key
+	m.confirmData = session
+	m.viewMode = ViewConfirm
+	m.err = nil
+	m.statusMessage = ""
+	return m, nil
+}
+
+// uploadEdits writes the edited object back, unless it changed since it was downloaded
+func (m Model) uploadEdits(session *editSession) tea.Cmd {
+	return tea.Cmd(func() tea.Msg {
+		err := m.s3Client.PutObjectIfMatch(context.Background(), m.bucket, session.key, session.edited, session.info, session.tags)
+		return fileEditedMsg{session: session, err: err}
+	})
+}
+
+// applyFileEdited reports the upload of edits. The temporary file is kept
+// when the object changed underneath, so the edits are not lost.
+func (m Model) applyFileEdited(msg fileEditedMsg) (tea.Model, tea.Cmd) {
+	m.loading = false
+	if errors.Is(msg.err, errObjectChanged) {
+		m.err = fmt.Errorf("'%s' was changed by someone else while you edited it; your version is kept in %s", displayKey(keyName(msg.session.key)), msg.session.path)
+		m.statusMessage = ""
+		return m, nil
+	}
+	if msg.err != nil {
+		m.err = fmt.Errorf("%w; your version is kept in %s", msg.err, msg.session.path)
+		m.statusMessage = ""
+		return m, nil
+	}
+	os.Remove(msg.session.path)
+	m.err = nil
+	m.statusMessage = fmt.Sprintf("✓ Uploaded changes to '%s'", displayKey(keyName(msg.session.key)))
+	return m, m.loadObjects()
+}
+
+// editConfirmMessage describes the edits awaiting confirmation with the first lines of their diff
+func editConfirmMessage(session *editSession) string {
+	diff := session.diff
+	more := 0
+	if len(diff) > editDiffLines {
+		more = len(diff) - editDiffLines
+		diff = diff[:editDiffLines]
+	}
+	text := strings.Join(diff, "\n")
+	if more > 0 {
+		text += fmt.Sprintf("\n… %d more diff lines", more)
+	}
+	return text
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"context"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// followInterval is how often a followed object is checked for new data
+const followInterval = 2 * time.Second
+
+// Messages for follow mode, which polls the previewed object like tail -f
+type followTickMsg struct {
+	key string
+	id  int
+}
+
+type followPolledMsg struct {
+	key      string
+	id       int
+	info     *ObjectInfo
+	start    int64
+	data     []byte
+	replaced bool // The object was rewritten rather than grown; data is its new tail
+	err      error
+}
+
+// followTick schedules the next poll of the followed object
+func (m Model) followTick() tea.Cmd {
+	key, id := m.previewFileName, m.followID
+	return tea.Tick(followInterval, func(time.Time) tea.Msg {
+		return followTickMsg{key: key, id: id}
+	})
+}
+
+// toggleFollow turns follow mode on, jumping to the tail, or off
+func (m *Model) toggleFollow() tea.Cmd {
+	m.followID++
+	if m.previewFollow {
+		m.previewFollow = false
+		m.statusMessage = "Stopped following"
+		return nil
+	}
+	if m.previewStream != nil || m.tableKind == tableColumnar || m.previewFilter != "" || m.previewVersion != "" {
+		m.statusMessage = "Follow mode is not available for compressed, Parquet, Avro and filtered objects, nor for old versions"
+		return nil
+	}
+
+	m.previewFollow = true
+	m.previewPretty = false
+	m.statusMessage = "Following; new data is shown as it arrives"
+	if m.previewEnd() < m.previewSize {
+		start := m.previewSize - previewChunkSize
+		if start < 0 {
+			start = 0
+		}
+		m.previewFetching = true
+		return tea.Batch(m.fetchPreviewRange(start, m.previewSize, chunkReplace), m.followTick())
+	}
+	m.previewScroll = m.maxPreviewScroll()
+	return m.followTick()
+}
+
+// pollFollow checks whether the followed object changed and reads what is new.
+// Objects that grew are assumed to have been appended to; objects that shrank
+// or changed at the same size were replaced and their tail is read again.
+func (m Model) pollFollow() tea.Cmd {
+	key, id := m.previewFileName, m.followID
+	size, etag, end := m.previewSize, m.previewETag, m.previewEnd()
+	return tea.Cmd(func() tea.Msg {
+		info, err := m.s3Client.HeadObject(context.Background(), m.bucket, key)
+		if err != nil {
+			return followPolledMsg{key: key, id: id, err: err}
+		}
+		msg := followPolledMsg{key: key, id: id, info: info}
+		switch {
+		case info.ETag == etag:
+			return msg
+		case info.Size > size && info.Size-size <= previewMaxWindow:
+			if end < size {
+				// The tail is not loaded; the new size is enough
+				return msg
+			}
+			msg.start = size
+			msg.data, msg.err = m.s3Client.GetObjectRange(context.Background(), m.bucket, key, size, info.Size)
+		default:
+			msg.replaced = true
+			msg.start = info.Size - previewChunkSize
+			if msg.start < 0 {
+				msg.start = 0
+			}
+			if info.Size > 0 {
+				msg.data, msg.err = m.s3Client.GetObjectRange(context.Background(), m.bucket, key, msg.start, info.Size)
+			}
+		}
+		return msg
+	})
+}
+
+// applyFollowPoll merges new data of the followed object into the preview and
+// keeps the view at the bottom if it was there
+func (m Model) applyFollowPoll(msg followPolledMsg) (tea.Model, tea.Cmd) {
+	if !m.previewFollow || msg.id != m.followID || msg.key != m.previewFileName {
+		return m, nil
+	}
+	if msg.err != nil {
+		m.previewFollow = false
+		m.err = msg.err
+		return m, nil
+	}
+	if msg.info.ETag == m.previewETag {
+		return m, m.followTick()
+	}
+
+	atBottom := m.previewScroll >= m.maxPreviewScroll()
+	m.previewETag = msg.info.ETag
+	m.previewSize = msg.info.Size
+
+	var cmd tea.Cmd
+	switch {
+	case msg.replaced:
+		var model tea.Model
+		model, cmd = m.applyPreviewChunk(previewChunkMsg{key: msg.key, start: msg.start, data: msg.data, mode: chunkReplace, eof: true})
+		m = model.(Model)
+		m.statusMessage = "Object was replaced; showing its new tail"
+		atBottom = true
+	case msg.data != nil:
+		var model tea.Model
+		model, cmd = m.applyPreviewChunk(previewChunkMsg{key: msg.key, start: msg.start, data: msg.data, mode: chunkAppend, eof: true})
+		m = model.(Model)
+	}
+	if atBottom {
+		m.previewScroll = m.maxPreviewScroll()
+	}
+	return m, tea.Batch(cmd, m.followTick())
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// ghostStyle greys out deleted objects shown in the browser
+var ghostStyle = lipgloss.NewStyle().
+	Foreground(lipgloss.Color("#555555")).
+	Italic(true)
+
+// mergeDeleted adds the deleted objects of a folder to its live listing.
+// Folders that still hold live objects are listed as live folders already.
+func mergeDeleted(objects, deleted []S3Object) []S3Object {
+	live := make(map[string]bool, len(objects))
+	for _, obj := range objects {
+		live[obj.Key] = true
+	}
+	for _, obj := range deleted {
+		if !live[obj.Key] {
+			live[obj.Key] = true
+			objects = append(objects, obj)
+		}
+	}
+	return objects
+}
+
+// undeleteTargets finds the delete markers hiding the targets: the target keys
+// themselves and, for folders, every deleted object below them
+func undeleteTargets(ctx context.Context, client *S3Client, bucket string, targets []string) (map[string]string, error) {
+	markers := make(map[string]string)
+	for _, target := range targets {
+		var versions []ObjectVersion
+		var err error
+		if strings.HasSuffix(target, "/") {
+			versions, _, err = client.ListObjectVersions(ctx, bucket, target, math.MaxInt)
+		} else {
+			versions, err = client.ListKeyVersions(ctx, bucket, target)
+		}
+		if err != nil {
+			return nil, err
+		}
+		for _, v := range versions {
+			if v.IsLatest && v.DeleteMarker {
+				markers[v.Key] = v.VersionID
+			}
+		}
+	}
+	if len(markers) == 0 {
+		return nil, fmt.Errorf("nothing to undelete: no deleted objects in the selection")
+	}
+	return markers, nil
+}
+
+// undeleteObjects removes the delete markers of every deleted object among
+// the targets, which brings back the version each one had before it was deleted
+func (m Model) undeleteObjects(targets []string) tea.Cmd {
+	client, bucket := m.s3Client, m.bucket
+	return tea.Cmd(func() tea.Msg {
+		markers, err := undeleteTargets(context.Background(), client, bucket, targets)
+		if err != nil {
+			return transferStartedMsg{err: err}
+		}
+		keys := make([]string, 0, len(markers))
+		for key := range markers {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		return startBatch(batchJob{
+			title: "Undeleting",
+			keys:  keys,
+			apply: func(ctx context.Context, key string) error {
+				return client.DeleteObjectVersion(ctx, bucket, key, markers[key])
+			},
+		})
+	})
+}
@@ -0,0 +1,278 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"strconv"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+const (
+	// hexBytesPerRow is the number of bytes shown on each hex dump row
+	hexBytesPerRow = 16
+	// hexWindowSize is the number of bytes fetched around the visible rows
+	hexWindowSize = 64 * 1024
+)
+
+type hexChunkMsg struct {
+	key   string
+	start int64
+	data  []byte
+	eof   bool
+	err   error
+}
+
+// enterHexView switches the preview to the hex dump, starting at offset
+func (m *Model) enterHexView(offset int64) tea.Cmd {
+	m.previewHex = true
+	m.hexOffset = offset &^ (hexBytesPerRow - 1)
+	if m.hexData == nil && m.previewStart == 0 && len(m.previewData) > 0 {
+		// Reuse the text window, which starts at the head of the object
+		m.hexData = m.previewData
+		m.hexStart = 0
+	}
+	if m.hexSummary == "" && m.hexData != nil && m.hexStart == 0 {
+		m.hexSummary = identifyMagic(m.hexData, m.previewSize)
+	}
+	m.previewWidth = m.calculatePreviewWidth()
+	return m.ensureHexWindow()
+}
+
+// hexRows returns the number of hex dump rows shown at once
+func (m Model) hexRows() int {
+	rows := m.previewVisibleHeight() - 2 // Leave room for the summary header
+	if rows < 1 {
+		rows = 1
+	}
+	return rows
+}
+
+// maxHexOffset returns the offset of the top row when the last byte is visible
+func (m Model) maxHexOffset() int64 {
+	lastRow := (m.previewSize - 1) &^ (hexBytesPerRow - 1)
+	top := lastRow - int64(m.hexRows()-1)*hexBytesPerRow
+	if top < 0 {
+		top = 0
+	}
+	return top
+}
+
+// ensureHexWindow fetches the bytes around the visible rows if they are not loaded
+func (m *Model) ensureHexWindow() tea.Cmd {
+	if m.hexOffset > m.maxHexOffset() {
+		m.hexOffset = m.maxHexOffset()
+	}
+	if m.hexOffset < 0 {
+		m.hexOffset = 0
+	}
+	if m.previewSize == 0 || m.hexFetching {
+		return nil
+	}
+
+	needEnd := m.clampPreviewEnd(m.hexOffset + int64(m.hexRows()*hexBytesPerRow))
+	if m.hexData != nil && m.hexOffset >= m.hexStart && needEnd <= m.hexStart+int64(len(m.hexData)) {
+		return nil
+	}
+
+	// Center the window on the visible rows
+	start := (m.hexOffset - hexWindowSize/4) &^ (hexBytesPerRow - 1)
+	if start < 0 {
+		start = 0
+	}
+	end := m.clampPreviewEnd(start + hexWindowSize)
+
+	m.hexFetching = true
+	key := m.previewFileName
+	return tea.Cmd(func() tea.Msg {
+		data, eof, err := m.readPreviewRange(context.Background(), key, start, end)
+		return hexChunkMsg{key: key, start: start, data: data, eof: eof, err: err}
+	})
+}
+
+// applyHexChunk stores a fetched hex window
+func (m Model) applyHexChunk(msg hexChunkMsg) (tea.Model, tea.Cmd) {
+	if msg.key != m.previewFileName {
+		return m, nil
+	}
+	m.hexFetching = false
+	if msg.err != nil {
+		m.err = msg.err
+		return m, nil
+	}
+	m.growPreviewSize(msg.start+int64(len(msg.data)), msg.eof)
+	m.hexData = msg.data
+	m.hexStart = msg.start
+	if m.hexSummary == "" && msg.start == 0 {
+		m.hexSummary = identifyMagic(msg.data, m.previewSize)
+	}
+	return m, m.ensureHexWindow()
+}
+
+// updateHexPreview handles key presses while the preview shows a hex dump
+func (m Model) updateHexPreview(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	page := int64(m.hexRows() * hexBytesPerRow)
+	switch msg.String() {
+	case "up", "k":
+		m.hexOffset -= hexBytesPerRow
+	case "down", "j":
+		m.hexOffset += hexBytesPerRow
+	case "pgup", "u":
+		m.hexOffset -= page
+	case "pgdown", "d":
+		m.hexOffset += page
+	case "home", "g":
+		m.hexOffset = 0
+	case "end", "G":
+		m.hexOffset = m.maxHexOffset()
+	case ":":
+		m.openPrompt("hex_jump", "Jump to Offset", "Offset (decimal, 0x hex, or -N from end):", "")
+		return m, nil
+	case "x":
+		if m.previewBinary {
+			return m, nil
+		}
+		// Back to the text view
+		m.previewHex = false
+		m.previewWidth = m.calculatePreviewWidth()
+		return m, nil
+	}
+	return m, m.ensureHexWindow()
+}
+
+// parseOffset parses a jump target: decimal, 0x-prefixed hex, or negative from the end
+func parseOffset(input string, size int64) (int64, error) {
+	input = strings.TrimSpace(input)
+	fromEnd := strings.HasPrefix(input, "-")
+	input = strings.TrimPrefix(input, "-")
+
+	offset, err := strconv.ParseInt(input, 0, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid offset '%s'", input)
+	}
+	if fromEnd {
+		offset = size - offset
+	}
+	if offset < 0 || offset >= size {
+		return 0, fmt.Errorf("offset %d is outside the object (size %d)", offset, size)
+	}
+	return offset, nil
+}
+
+// viewHexDump renders the visible rows of the hex dump with offset and ASCII columns
+func (m Model) viewHexDump() string {
+	var b strings.Builder
+
+	if m.hexSummary != "" {
+		b.WriteString(successStyle.Render(m.hexSummary))
+		b.WriteString("\n\n")
+	}
+
+	// Offset column wide enough for the largest offset
+	digits := len(strconv.FormatInt(m.previewSize, 16))
+	if digits < 8 {
+		digits = 8
+	}
+
+	for row := 0; row < m.hexRows(); row++ {
+		offset := m.hexOffset + int64(row*hexBytesPerRow)
+		if offset >= m.previewSize {
+			break
+		}
+
+		var hexPart, asciiPart strings.Builder
+		for i := 0; i < hexBytesPerRow; i++ {
+			if i == hexBytesPerRow/2 {
+				hexPart.WriteString(" ")
+			}
+			pos := offset + int64(i) - m.hexStart
+			if offset+int64(i) >= m.previewSize || m.hexData == nil || pos < 0 || pos >= int64(len(m.hexData)) {
+				hexPart.WriteString("   ")
+				continue
+			}
+			c := m.hexData[pos]
+			fmt.Fprintf(&hexPart, "%02x ", c)
+			if c >= 32 && c <= 126 {
+				asciiPart.WriteByte(c)
+			} else {
+				asciiPart.WriteByte('.')
+			}
+		}
+		fmt.Fprintf(&b, "%0*x  %s |%-16s|\n", digits, offset, hexPart.String(), asciiPart.String())
+	}
+
+	return b.String()
+}
+
+// hexPosition describes the visible byte range as a share of the object
+func (m Model) hexPosition() string {
+	end := m.hexOffset + int64(m.hexRows()*hexBytesPerRow)
+	if end > m.previewSize {
+		end = m.previewSize
+	}
+	position := fmt.Sprintf("[Bytes 0x%x-0x%x | %d%% of %s]", m.hexOffset, end, end*100/m.previewSize, formatSize(m.previewSize))
+	if m.hexFetching {
+		position += " loading..."
+	}
+	return position
+}
+
+// identifyMagic recognises common binary formats from their leading bytes and
+// returns a one-line summary, or a generic description if the format is unknown
+func identifyMagic(head []byte, size int64) string {
+	switch {
+	case len(head) >= 20 && bytes.HasPrefix(head, []byte("\x7fELF")):
+		class := map[byte]string{1: "32-bit", 2: "64-bit"}[head[4]]
+		var order binary.ByteOrder = binary.LittleEndian
+		endian := "LSB"
+		if head[5] == 2 {
+			order = binary.BigEndian
+			endian = "MSB"
+		}
+		kind := map[uint16]string{1: "relocatable", 2: "executable", 3: "shared object", 4: "core file"}[order.Uint16(head[16:18])]
+		machine := map[uint16]string{3: "x86", 0x28: "ARM", 0x3e: "x86-64", 0xb7: "AArch64", 0xf3: "RISC-V"}[order.Uint16(head[18:20])]
+		return strings.Join(strings.Fields(fmt.Sprintf("ELF %s %s %s %s", class, endian, kind, machine)), " ")
+
+	case len(head) >= 26 && bytes.HasPrefix(head, []byte("\x89PNG\r\n\x1a\n")) && string(head[12:16]) == "IHDR":
+		width := binary.BigEndian.Uint32(head[16:20])
+		height := binary.BigEndian.Uint32(head[20:24])
+		colour := map[byte]string{0: "greyscale", 2: "RGB", 3: "palette", 4: "greyscale+alpha", 6: "RGBA"}[head[25]]
+		return fmt.Sprintf("PNG image, %d x %d, %d-bit %s", width, height, head[24], colour)
+
+	case len(head) >= 10 && head[0] == 0x1f && head[1] == 0x8b:
+		summary := "gzip compressed data"
+		if head[2] == 8 {
+			summary += ", deflate"
+		}
+		if mtime := binary.LittleEndian.Uint32(head[4:8]); mtime != 0 {
+			summary += fmt.Sprintf(", mtime %d", mtime)
+		}
+		// FNAME flag: zero-terminated original file name after the header
+		if head[3]&0x08 != 0 && head[3]&0x04 == 0 {
+			if end := bytes.IndexByte(head[10:], 0); end > 0 {
+				summary += fmt.Sprintf(", was \"%s\"", displayKey(string(head[10:10+end])))
+			}
+		}
+		return summary
+
+	case len(head) >= 30 && bytes.HasPrefix(head, []byte("PK\x03\x04")):
+		method := map[uint16]string{0: "stored", 8: "deflate", 12: "bzip2", 14: "lzma", 93: "zstd"}[binary.LittleEndian.Uint16(head[8:10])]
+		nameLen := int(binary.LittleEndian.Uint16(head[26:28]))
+		summary := "Zip archive"
+		if 30+nameLen <= len(head) {
+			summary += fmt.Sprintf(", first entry \"%s\"", displayKey(string(head[30:30+nameLen])))
+		}
+		if method != "" {
+			summary += ", " + method
+		}
+		return summary
+
+	case bytes.HasPrefix(head, []byte("PAR1")):
+		return fmt.Sprintf("Apache Parquet file, %s", formatSize(size))
+	}
+
+	return fmt.Sprintf("Binary data, %s", formatSize(size))
+}
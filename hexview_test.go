@@ -0,0 +1,79 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseOffset(t *testing.T) {
+	const size = 4096
+	tests := []struct {
+		input   string
+		want    int64
+		wantErr bool
+	}{
+		{"0", 0, false},
+		{"100", 100, false},
+		{" 42 ", 42, false},
+		{"0x100", 256, false},
+		{"0X1f", 31, false},
+		{"4095", 4095, false},
+		{"-16", 4080, false},
+		{"-0x10", 4080, false},
+		{"-4096", 0, false},
+		{"4096", 0, true},
+		{"-4097", 0, true},
+		{"--5", 0, true},
+		{"", 0, true},
+		{"0x", 0, true},
+		{"abc", 0, true},
+		{"12kb", 0, true},
+	}
+	for _, tt := range tests {
+		got, err := parseOffset(tt.input, size)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("parseOffset(%q) = %d, want an error", tt.input, got)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("parseOffset(%q) = %d, %v, want %d", tt.input, got, err, tt.want)
+		}
+	}
+}
+
+func TestIdentifyMagic(t *testing.T) {
+	const size = 1 << 20
+	zipHeader := func(method byte, nameLen byte, name string) string {
+		return "PK\x03\x04" + "\x14\x00" + "\x00\x00" + string([]byte{method, 0}) + strings.Repeat("\x00", 16) +
+			string([]byte{nameLen, 0}) + "\x00\x00" + name
+	}
+	tests := []struct {
+		name string
+		head string
+		want string
+	}{
+		{"elf 64-bit executable", "\x7fELF\x02\x01\x01" + strings.Repeat("\x00", 9) + "\x02\x00\x3e\x00", "ELF 64-bit LSB executable x86-64"},
+		{"elf big-endian shared object", "\x7fELF\x01\x02\x01" + strings.Repeat("\x00", 9) + "\x00\x03\x00\x28", "ELF 32-bit MSB shared object ARM"},
+		{"elf unknown machine", "\x7fELF\x02\x01\x01" + strings.Repeat("\x00", 9) + "\x01\x00\x99\x00", "ELF 64-bit LSB relocatable"},
+		{"elf truncated", "\x7fELF\x02\x01", "Binary data, " + formatSize(size)},
+		{"png", "\x89PNG\r\n\x1a\n" + "\x00\x00\x00\x0dIHDR" + "\x00\x00\x02\x80" + "\x00\x00\x01\xe0" + "\x08\x06", "PNG image, 640 x 480, 8-bit RGBA"},
+		{"png without IHDR", "\x89PNG\r\n\x1a\n" + strings.Repeat("\x00", 18), "Binary data, " + formatSize(size)},
+		{"gzip with name", "\x1f\x8b\x08\x08" + "\x00\x00\x00\x00" + "\x00\x03" + "app.log\x00", `gzip compressed data, deflate, was "app.log"`},
+		{"gzip with mtime", "\x1f\x8b\x08\x00" + "\x00\xf1\x53\x65" + "\x00\x03", "gzip compressed data, deflate, mtime 1700000000"},
+		{"gzip name after extra field", "\x1f\x8b\x08\x0c" + "\x00\x00\x00\x00" + "\x00\x03" + "app.log\x00", "gzip compressed data, deflate"},
+		{"gzip name with escape", "\x1f\x8b\x08\x08" + "\x00\x00\x00\x00" + "\x00\x03" + "a\x1b[31m\x00", `gzip compressed data, deflate, was "` + displayKey("a\x1b[31m") + `"`},
+		{"zip", zipHeader(8, 5, "a.txt"), `Zip archive, first entry "a.txt", deflate`},
+		{"zip name cut off", zipHeader(0, 200, "a.txt"), "Zip archive, stored"},
+		{"zip unknown method", zipHeader(99, 5, "a.txt"), `Zip archive, first entry "a.txt"`},
+		{"parquet", "PAR1\x15\x04", "Apache Parquet file, " + formatSize(size)},
+		{"empty", "", "Binary data, " + formatSize(size)},
+		{"unknown", "\x00\x01\x02\x03", "Binary data, " + formatSize(size)},
+	}
+	for _, tt := range tests {
+		if got := identifyMagic([]byte(tt.head), size); got != tt.want {
+			t.Errorf("identifyMagic(%s) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
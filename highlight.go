@@ -0,0 +1,291 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"path/filepath"
+	"strings"
+
+	"github.com/alecthomas/chroma/v2"
+	"github.com/alecthomas/chroma/v2/lexers"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Token styles for highlighted previews. lipgloss downsamples the colours to
+// the terminal's profile and drops them entirely when colour is unsupported.
+var (
+	keywordStyle  = lipgloss.NewStyle().Foreground(lipgloss.Color("#5f87ff")).Bold(true)
+	constantStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#d787d7"))
+	stringStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("#5faf5f"))
+	numberStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("#d787d7"))
+	commentStyle  = lipgloss.NewStyle().Foreground(lipgloss.Color("#808080")).Italic(true)
+	nameStyle     = lipgloss.NewStyle().Foreground(lipgloss.Color("#ff8700"))
+	functionStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#d7af5f"))
+	punctStyle    = lipgloss.NewStyle().Foreground(lipgloss.Color("#999999"))
+
+	// columnStyles colour the columns of delimited files in turn
+	columnStyles = []lipgloss.Style{
+		lipgloss.NewStyle().Foreground(lipgloss.Color("#5f87ff")),
+		lipgloss.NewStyle().Foreground(lipgloss.Color("#5faf5f")),
+		lipgloss.NewStyle().Foreground(lipgloss.Color("#ff8700")),
+		lipgloss.NewStyle().Foreground(lipgloss.Color("#d787d7")),
+		lipgloss.NewStyle().Foreground(lipgloss.Color("#00afaf")),
+		lipgloss.NewStyle().Foreground(lipgloss.Color("#d7af5f")),
+	}
+)
+
+// mediaType returns the Content-Type without parameters, lower-cased
+func mediaType(contentType string) string {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return strings.ToLower(strings.TrimSpace(contentType))
+	}
+	return mediaType
+}
+
+// previewDelimiter returns the field separator for CSV and TSV objects, or 0
+func previewDelimiter(key, contentType string) rune {
+	switch strings.ToLower(filepath.Ext(keyName(key))) {
+	case ".csv":
+		return ','
+	case ".tsv", ".tab":
+		return '\t'
+	}
+	switch mediaType(contentType) {
+	case "text/csv":
+		return ','
+	case "text/tab-separated-values":
+		return '\t'
+	}
+	return 0
+}
+
+// previewLexer picks a lexer from the key's extension, falling back to the
+// Content-Type. It returns nil when the object is plain text.
+func previewLexer(key, contentType string) chroma.Lexer {
+	lexer := lexers.Match(keyName(key))
+	if lexer == nil && contentType != "" {
+		lexer = lexers.MatchMimeType(mediaType(contentType))
+	}
+	if lexer == nil || lexer.Config().Name == "plaintext" {
+		return nil
+	}
+	return chroma.Coalesce(lexer)
+}
+
+// tokenStyle returns the style for a token type, and false if it is unstyled
+func tokenStyle(tokenType chroma.TokenType) (lipgloss.Style, bool) {
+	switch {
+	case tokenType.InCategory(chroma.Comment):
+		return commentStyle, true
+	case tokenType == chroma.KeywordConstant:
+		return constantStyle, true
+	case tokenType.InCategory(chroma.Keyword):
+		return keywordStyle, true
+	case tokenType.InSubCategory(chroma.LiteralString):
+		return stringStyle, true
+	case tokenType.InSubCategory(chroma.LiteralNumber):
+		return numberStyle, true
+	case tokenType == chroma.NameFunction || tokenType == chroma.NameClass || tokenType == chroma.NameBuiltin:
+		return functionStyle, true
+	case tokenType == chroma.NameTag || tokenType == chroma.NameAttribute || tokenType == chroma.NameDecorator:
+		return nameStyle, true
+	case tokenType.InCategory(chroma.Operator) || tokenType == chroma.Punctuation:
+		return punctStyle, true
+	}
+	return lipgloss.Style{}, false
+}
+
+// highlightLine colours a single preview line. Lines are tokenised on their
+// own because the window may start anywhere in the object, so constructs that
+// span lines (block comments, multi-line strings) are only partly coloured.
+func highlightLine(lexer chroma.Lexer, line string) string {
+	iterator, err := lexer.Tokenise(nil, line)
+	if err != nil {
+		return line
+	}
+
+	var b strings.Builder
+	for _, token := range iterator.Tokens() {
+		value := strings.TrimSuffix(token.Value, "\n")
+		if value == "" {
+			continue
+		}
+		if style, ok := tokenStyle(token.Type); ok {
+			b.WriteString(style.Render(value))
+		} else {
+			b.WriteString(value)
+		}
+	}
+	return b.String()
+}
+
+// highlightDelimited colours each column of a CSV or TSV line in turn.
+// Separators inside double-quoted fields do not start a new column.
+func highlightDelimited(line string, delimiter rune) string {
+	var b, field strings.Builder
+	column := 0
+	quoted := false
+	flush := func() {
+		b.WriteString(columnStyles[column%len(columnStyles)].Render(field.String()))
+		field.Reset()
+	}
+
+	for _, r := range line {
+		switch {
+		case r == '"':
+			quoted = !quoted
+			field.WriteRune(r)
+		case r == delimiter && !quoted:
+			flush()
+			b.WriteString(punctStyle.Render(string(r)))
+			column++
+		default:
+			field.WriteRune(r)
+		}
+	}
+	flush()
+	return b.String()
+}
+
+// highlightPreviewLine colours a preview line for the current object
+func (m Model) highlightPreviewLine(line string) string {
+	switch {
+	case m.previewDelimiter != 0:
+		return highlightDelimited(line, m.previewDelimiter)
+	case m.previewLexer != nil:
+		return highlightLine(m.previewLexer, line)
+	}
+	return line
+}
+
+// prettyKind returns "json" or "xml" if the object can be pretty-printed, or ""
+func prettyKind(key, contentType string, head []byte) string {
+	switch strings.ToLower(filepath.Ext(keyName(key))) {
+	case ".json", ".jsonl", ".ndjson", ".geojson":
+		return "json"
+	case ".xml", ".svg", ".xsd", ".xsl", ".rss", ".atom":
+		return "xml"
+	}
+
+	media := mediaType(contentType)
+	switch {
+	case strings.HasSuffix(media, "json"):
+		return "json"
+	case strings.HasSuffix(media, "xml"):
+		return "xml"
+	}
+
+	// Sniff objects without a telling extension or Content-Type
+	trimmed := bytes.TrimLeft(head, " \t\r\n\ufeff")
+	switch {
+	case len(trimmed) > 0 && (trimmed[0] == '{' || trimmed[0] == '['):
+		return "json"
+	case len(trimmed) > 0 && trimmed[0] == '<':
+		return "xml"
+	}
+	return ""
+}
+
+// prettyPrint re-indents a JSON or XML document. A stream of JSON values (as
+// in JSON Lines) is indented value by value.
+func prettyPrint(kind string, data []byte) ([]byte, error) {
+	switch kind {
+	case "json":
+		return prettyJSON(data)
+	case "xml":
+		return prettyXML(data)
+	}
+	return nil, fmt.Errorf("pretty-printing supports JSON and XML only")
+}
+
+// prettyJSON indents every JSON value in data
+func prettyJSON(data []byte) ([]byte, error) {
+	var out bytes.Buffer
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	for {
+		var value json.RawMessage
+		err := decoder.Decode(&value)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse JSON: %w", err)
+		}
+		if err := json.Indent(&out, value, "", "  "); err != nil {
+			return nil, fmt.Errorf("failed to indent JSON: %w", err)
+		}
+		out.WriteByte('\n')
+	}
+	return out.Bytes(), nil
+}
+
+// prettyXML indents an XML document. Raw tokens are used so that namespace
+// prefixes are written back exactly as they appear in the source.
+func prettyXML(data []byte) ([]byte, error) {
+	var out bytes.Buffer
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	decoder.Strict = false
+	encoder := xml.NewEncoder(&out)
+	encoder.Indent("", "  ")
+
+	for {
+		token, err := decoder.RawToken()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse XML: %w", err)
+		}
+
+		switch t := token.(type) {
+		case xml.CharData:
+			// Whitespace between elements is replaced by the new indentation
+			if len(bytes.TrimSpace(t)) == 0 {
+				continue
+			}
+			token = xml.CharData(bytes.TrimSpace(t))
+		case xml.StartElement:
+			t.Name = prefixedName(t.Name)
+			attrs := make([]xml.Attr, len(t.Attr))
+			for i, attr := range t.Attr {
+				attrs[i] = xml.Attr{Name: prefixedName(attr.Name), Value: attr.Value}
+			}
+			t.Attr = attrs
+			token = t
+		case xml.EndElement:
+			t.Name = prefixedName(t.Name)
+			token = t
+		}
+		if err := encoder.EncodeToken(xml.CopyToken(token)); err != nil {
+			return nil, fmt.Errorf("failed to indent XML: %w", err)
+		}
+		switch token.(type) {
+		case xml.ProcInst, xml.Directive:
+			// The encoder does not indent after the prolog
+			if err := encoder.Flush(); err != nil {
+				return nil, fmt.Errorf("failed to indent XML: %w", err)
+			}
+			out.WriteByte('\n')
+		}
+	}
+	if err := encoder.Flush(); err != nil {
+		return nil, fmt.Errorf("failed to indent XML: %w", err)
+	}
+	out.WriteByte('\n')
+	return out.Bytes(), nil
+}
+
+// prefixedName folds a raw namespace prefix into the local name so the encoder
+// writes "prefix:name" instead of inventing xmlns attributes
+func prefixedName(name xml.Name) xml.Name {
+	if name.Space == "" {
+		return name
+	}
+	return xml.Name{Local: name.Space + ":" + name.Local}
+}
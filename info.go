@@ -0,0 +1,236 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// infoField is one line of the object info panel
+type infoField struct {
+	name  string
+	value string
+}
+
+// objectInfoMsg carries the metadata and tags of an object for the info panel
+type objectInfoMsg struct {
+	key     string
+	info    *ObjectInfo
+	tags    map[string]string
+	tagsErr error // Tags are optional; a missing permission only hides them
+	err     error
+}
+
+// clipboardMsg reports the result of copying to the clipboard
+type clipboardMsg struct {
+	what string
+	err  error
+}
+
+// inspectObject reads the metadata and tags of an object for the info panel
+func (m Model) inspectObject(key string) tea.Cmd {
+	return tea.Cmd(func() tea.Msg {
+		info, err := m.s3Client.HeadObject(context.Background(), m.bucket, key)
+		if err != nil {
+			return objectInfoMsg{key: key, err: err}
+		}
+		tags, tagsErr := m.s3Client.GetObjectTagging(context.Background(), m.bucket, key)
+		return objectInfoMsg{key: key, info: info, tags: tags, tagsErr: tagsErr}
+	})
+}
+
+// copyCmd copies text to the clipboard, naming it what in the result
+func copyCmd(what, text string) tea.Cmd {
+	return tea.Cmd(func() tea.Msg {
+		return clipboardMsg{what: what, err: copyToClipboard(text)}
+	})
+}
+
+// infoFields lists the metadata and tags of an object, leaving out headers it does not have
+func infoFields(key string, info *ObjectInfo, tags map[string]string, tagsErr error) []infoField {
+	var fields []infoField
+	add := func(name, value string) {
+		if value != "" {
+			fields = append(fields, infoField{name, value})
+		}
+	}
+	formatTime := func(t time.Time) string {
+		if t.IsZero() {
+			return ""
+		}
+		return t.Local().Format("2006-01-02 15:04:05 MST")
+	}
+
+	add("Key", key)
+	add("Size", fmt.Sprintf("%s (%d bytes)", formatSize(info.Size), info.Size))
+	add("Last-Modified", formatTime(info.LastModified))
+	add("ETag", info.ETag)
+	add("Version ID", info.VersionID)
+	add("Content-Type", info.ContentType)
+	add("Content-Encoding", info.ContentEncoding)
+	add("Content-Disposition", info.ContentDisposition)
+	add("Content-Language", info.ContentLanguage)
+	add("Cache-Control", info.CacheControl)
+	add("Expires", info.Expires)
+	add("Website Redirect", info.WebsiteRedirect)
+	storageClass := info.StorageClass
+	if storageClass == "" {
+		// HEAD leaves out the header for the default class
+		storageClass = "STANDARD"
+	}
+	add("Storage Class", storageClass)
+	add("Archive Status", info.ArchiveStatus)
+	add("Restore", describeRestore(info.Restore))
+	if info.PartsCount > 0 {
+		add("Parts", fmt.Sprintf("%d (multipart upload)", info.PartsCount))
+	}
+	add("Encryption", info.ServerSideEncryption)
+	add("KMS Key ID", info.SSEKMSKeyID)
+	if info.BucketKeyEnabled {
+		add("Bucket Key", "enabled")
+	}
+	add("SSE-C Algorithm", info.SSECustomerAlgorithm)
+	add("Object Lock Mode", info.ObjectLockMode)
+	add("Retain Until", formatTime(info.ObjectLockRetainUntil))
+	add("Legal Hold", info.ObjectLockLegalHold)
+	add("Replication", info.ReplicationStatus)
+	add("Lifecycle Expiry", info.Expiration)
+
+	names := make([]string, 0, len(info.Metadata))
+	for name := range info.Metadata {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		add("x-amz-meta-"+name, info.Metadata[name])
+	}
+
+	if tagsErr != nil {
+		add("Tags", fmt.Sprintf("unavailable (%v)", tagsErr))
+	}
+	for _, tag := range tagFields(tags) {
+		// Tag values may be empty, so they are added even then
+		fields = append(fields, infoField{"tag:" + tag.name, tag.value})
+	}
+	return fields
+}
+
+// applyObjectInfo opens the info panel on the metadata of an object
+func (m Model) applyObjectInfo(msg objectInfoMsg) (tea.Model, tea.Cmd) {
+	m.loading = false
+	if msg.err != nil {
+		m.err = msg.err
+		return m, nil
+	}
+	if m.viewMode != ViewInfo {
+		m.infoReturn = m.viewMode
+		m.infoCursor = 0
+	}
+	m.infoKey = msg.key
+	m.infoFields = infoFields(msg.key, msg.info, msg.tags, msg.tagsErr)
+	if m.infoCursor >= len(m.infoFields) {
+		m.infoCursor = len(m.infoFields) - 1
+	}
+	m.viewMode = ViewInfo
+	m.err = nil
+	m.statusMessage = ""
+	return m, nil
+}
+
+// updateInfo handles info panel updates
+func (m Model) updateInfo(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c", "q":
+		return m, tea.Quit
+	case "esc", "backspace", "h", "left", "i":
+		m.viewMode = m.infoReturn
+		m.infoFields = nil
+		m.statusMessage = ""
+	case "up", "k":
+		if m.infoCursor > 0 {
+			m.infoCursor--
+		}
+	case "down", "j":
+		if m.infoCursor < len(m.infoFields)-1 {
+			m.infoCursor++
+		}
+	case "g", "home":
+		m.infoCursor = 0
+	case "G", "end":
+		m.infoCursor = len(m.infoFields) - 1
+	case "y", "enter":
+		// Copy the value under the cursor
+		if m.infoCursor < len(m.infoFields) {
+			field := m.infoFields[m.infoCursor]
+			return m, copyCmd(field.name, field.value)
+		}
+	case "Y":
+		// Copy all fields as "name: value" lines
+		var b strings.Builder
+		for _, field := range m.infoFields {
+			fmt.Fprintf(&b, "%s: %s\n", field.name, field.value)
+		}
+		return m, copyCmd("all fields", b.String())
+	case "r":
+		m.loading = true
+		return m, m.inspectObject(m.infoKey)
+	case "t":
+		m.loading = true
+		return m, m.loadTags(m.infoKey)
+	}
+	return m, nil
+}
+
+// viewInfo renders the object info panel
+func (m Model) viewInfo() string {
+	var s strings.Builder
+
+	s.WriteString(titleStyle.Render(fmt.Sprintf("Info: %s", displayKey(keyName(m.infoKey)))))
+	s.WriteString("\n\n")
+
+	nameWidth := 0
+	for _, field := range m.infoFields {
+		nameWidth = max(nameWidth, len(field.name))
+	}
+	valueWidth := max(m.width-nameWidth-20, 20)
+
+	var content strings.Builder
+	height := max(m.height-10, 5)
+	start := 0
+	if m.infoCursor >= height {
+		start = m.infoCursor - height + 1
+	}
+	for i := start; i < len(m.infoFields) && i < start+height; i++ {
+		field := m.infoFields[i]
+		cursor := " "
+		if i == m.infoCursor {
+			cursor = ">"
+		}
+		line := fmt.Sprintf("%s %-*s  %s", cursor, nameWidth, displayKey(field.name), truncateString(displayKey(field.value), valueWidth))
+		if i == m.infoCursor {
+			line = selectedStyle.Render(line)
+		}
+		content.WriteString(line + "\n")
+	}
+
+	if m.err != nil {
+		content.WriteString("\n" + errorStyle.Render(fmt.Sprintf("Error: %s", m.err.Error())))
+	} else if m.statusMessage != "" {
+		content.WriteString("\n" + successStyle.Render(m.statusMessage))
+	}
+	s.WriteString(previewStyle.Render(strings.TrimSuffix(content.String(), "\n")))
+
+	s.WriteString("\n\n")
+	s.WriteString(helpStyle.Render("↑/k,↓/j: move • y/enter: copy value • Y: copy all • t: edit tags • r: refresh • esc: back • q: quit"))
+
+	if m.width > 0 && m.height > 0 {
+		centered := centerStyle.Width(m.width).Render(lipgloss.NewStyle().Align(lipgloss.Left).Render(s.String()))
+		return verticalCenterStyle.Height(m.height).Render(centered)
+	}
+	return s.String()
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// S3 keys are arbitrary UTF-8 strings: they may start with "/", contain empty
+// segments ("a//b"), "." and ".." segments, control characters or characters
+// that are illegal in local filenames. Folder prefixes are always kept with
+// their trailing "/", and keys are never passed through path/filepath, which
+// would clean them into a different key.
+
+// keyName returns the last segment of a key. For folder prefixes (ending in
+// "/") this is the segment before the trailing slash, which may be empty.
+func keyName(key string) string {
+	trimmed := strings.TrimSuffix(key, "/")
+	if i := strings.LastIndex(trimmed, "/"); i >= 0 {
+		return trimmed[i+1:]
+	}
+	return trimmed
+}
+
+// parentPrefix returns the folder prefix containing key ("" for the root)
+func parentPrefix(key string) string {
+	trimmed := strings.TrimSuffix(key, "/")
+	if i := strings.LastIndex(trimmed, "/"); i >= 0 {
+		return trimmed[:i+1]
+	}
+	return ""
+}
+
+// displayKey makes a key or key segment safe to print in the terminal by
+// escaping control and other non-printable characters
+func displayKey(key string) string {
+	var b strings.Builder
+	for i := 0; i < len(key); {
+		r, size := utf8.DecodeRuneInString(key[i:])
+		switch {
+		case r == utf8.RuneError && size == 1:
+			// Invalid UTF-8 byte
+			fmt.Fprintf(&b, `\x%02x`, key[i])
+		case !unicode.IsPrint(r) && r != ' ':
+			if r < 0x10000 {
+				fmt.Fprintf(&b, `\u%04x`, r)
+			} else {
+				fmt.Fprintf(&b, `\U%08x`, r)
+			}
+		default:
+			b.WriteRune(r)
+		}
+		i += size
+	}
+	return b.String()
+}
+
+// displayName returns the printable name of an object, with "/" appended to folders
+func displayName(obj S3Object) string {
+	name := displayKey(keyName(obj.Key))
+	if obj.IsDir {
+		name += "/"
+	}
+	return name
+}
+
+// localFileName turns a key into a file name that is safe to create in the
+// current directory: path separators and characters that are illegal on common
+// filesystems are replaced, and "", "." and ".." can never escape the directory
+func localFileName(key string) string {
+	name := strings.Map(func(r rune) rune {
+		switch {
+		case r < 0x20 || r == 0x7f:
+			return '_'
+		case strings.ContainsRune(`/\:*?"<>|`, r):
+			return '_'
+		}
+		return r
+	}, keyName(key))
+
+	// Trailing dots and spaces are stripped by Windows, so avoid them too
+	name = strings.TrimRight(name, ". ")
+	if name == "" {
+		name = "_"
+	}
+	return name
+}
+
+// copySource builds the URL-encoded CopySource value for an object. Every key
+// segment is escaped so that spaces, "+", "#", "?", "%" and non-ASCII characters
+// survive; "/" separators are kept as-is.
+func copySource(bucket, key string) string {
+	segments := strings.Split(key, "/")
+	for i, segment := range segments {
+		segments[i] = strings.ReplaceAll(url.QueryEscape(segment), "+", "%20")
+	}
+	return bucket + "/" + strings.Join(segments, "/")
+}
+
+// versionCopySource builds the CopySource value for a version of an object,
+// or for its current version when versionID is ""
+func versionCopySource(bucket, key, versionID string) string {
+	if versionID == "" {
+		return copySource(bucket, key)
+	}
+	return copySource(bucket, key) + "?versionId=" + url.QueryEscape(versionID)
+}
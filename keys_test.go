@@ -0,0 +1,126 @@
+package main
+
+import (
+	"bufio"
+	"net/url"
+	"os"
+	"strings"
+	"testing"
+	"unicode"
+)
+
+// hostileKeys reads the key corpus in testdata/hostile-keys.txt. Lines are
+// used as-is, so leading and trailing spaces are part of the keys.
+func hostileKeys(t *testing.T) []string {
+	t.Helper()
+	file, err := os.Open("testdata/hostile-keys.txt")
+	if err != nil {
+		t.Fatalf("failed to open key corpus: %v", err)
+	}
+	defer file.Close()
+
+	var keys []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		keys = append(keys, line)
+	}
+	if err := scanner.Err(); err != nil {
+		t.Fatalf("failed to read key corpus: %v", err)
+	}
+	if len(keys) == 0 {
+		t.Fatal("key corpus is empty")
+	}
+	return keys
+}
+
+func TestCopySourceRoundTrip(t *testing.T) {
+	for _, key := range hostileKeys(t) {
+		for _, k := range []string{key, "folder/" + key, key + "/"} {
+			source := copySource("test-bucket", k)
+			escaped, ok := strings.CutPrefix(source, "test-bucket/")
+			if !ok {
+				t.Errorf("copySource(%q) = %q, want the bucket first", k, source)
+				continue
+			}
+			unescaped, err := url.PathUnescape(escaped)
+			if err != nil {
+				t.Errorf("copySource(%q) = %q, which does not unescape: %v", k, source, err)
+				continue
+			}
+			if unescaped != k {
+				t.Errorf("copySource(%q) = %q, which unescapes to %q", k, source, unescaped)
+			}
+		}
+	}
+}
+
+func TestLocalFileName(t *testing.T) {
+	for _, key := range hostileKeys(t) {
+		for _, k := range []string{key, "folder/" + key, key + "/"} {
+			name := localFileName(k)
+			switch {
+			case name == "", name == ".", name == "..":
+				t.Errorf("localFileName(%q) = %q, which is not a file name", k, name)
+			case strings.ContainsAny(name, `/\`):
+				t.Errorf("localFileName(%q) = %q, which contains a path separator", k, name)
+			case strings.IndexFunc(name, unicode.IsControl) >= 0:
+				t.Errorf("localFileName(%q) = %q, which contains a control character", k, name)
+			}
+		}
+	}
+}
+
+func TestDisplayKey(t *testing.T) {
+	keys := append(hostileKeys(t), "bell\a.txt", "escape\x1b[31m.txt", "invalid-\xff.txt")
+	for _, key := range keys {
+		shown := displayKey(key)
+		if i := strings.IndexFunc(shown, func(r rune) bool { return !unicode.IsPrint(r) && r != ' ' }); i >= 0 {
+			t.Errorf("displayKey(%q) = %q, which contains a non-printable character", key, shown)
+		}
+	}
+}
+
+func TestKeyNameAndParentPrefix(t *testing.T) {
+	tests := []struct {
+		key    string
+		name   string
+		parent string
+	}{
+		{"file.txt", "file.txt", ""},
+		{"a/b/file.txt", "file.txt", "a/b/"},
+		{"folder/", "folder", ""},
+		{"a/b/", "b", "a/"},
+		{"/leading-slash.txt", "leading-slash.txt", "/"},
+		{"//double-leading-slash.txt", "double-leading-slash.txt", "//"},
+		{"/", "", ""},
+		{"//", "", "/"},
+		{"folder//empty-segment.txt", "empty-segment.txt", "folder//"},
+		{"folder//", "", "folder/"},
+		{"a/../../b.txt", "b.txt", "a/../../"},
+		{"..", "..", ""},
+		{"../", "..", ""},
+		{"trailing-space ", "trailing-space ", ""},
+		{"", "", ""},
+	}
+	for _, tt := range tests {
+		if got := keyName(tt.key); got != tt.name {
+			t.Errorf("keyName(%q) = %q, want %q", tt.key, got, tt.name)
+		}
+		if got := parentPrefix(tt.key); got != tt.parent {
+			t.Errorf("parentPrefix(%q) = %q, want %q", tt.key, got, tt.parent)
+		}
+	}
+
+	// Every key of the corpus splits into its parent and name without losing anything
+	for _, key := range hostileKeys(t) {
+		for _, k := range []string{key, "folder/" + key, key + "/"} {
+			if got := parentPrefix(k) + keyName(k); got != strings.TrimSuffix(k, "/") {
+				t.Errorf("parentPrefix(%q) + keyName(%q) = %q, want %q", k, k, got, strings.TrimSuffix(k, "/"))
+			}
+		}
+	}
+}
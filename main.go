@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"flag"
 	"fmt"
 	"os"
 
@@ -11,12 +12,24 @@ import (
 func main() {
 	if len(os.Args) < 2 {
 		fmt.Println("Usage: s4 <bucket-name>")
+		fmt.Println("       s4 presign [options] <bucket-name> <key-or-prefix/>...")
 		fmt.Println("\nS4 is a TUI (Terminal User Interface) for browsing S3 buckets.")
 		fmt.Println("It reads configuration from .s3cfg file (compatible with s3cmd).")
 		fmt.Println("\nExample: s4 my-bucket")
 		os.Exit(1)
 	}
 
+	// Export presigned URLs without starting the TUI
+	if os.Args[1] == "presign" {
+		if err := runPresignCommand(os.Args[2:]); err != nil {
+			if err != flag.ErrHelp {
+				fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+			}
+			os.Exit(1)
+		}
+		return
+	}
+
 	bucketName := os.Args[1]
 
 	// Load S3 configuration
@@ -56,11 +69,20 @@ func main() {
 		os.Exit(1)
 	}
 
+	// Load s4 options; they are all optional
+	settings, err := LoadSettings()
+	if err != nil {
+		fmt.Printf("Error loading settings: %s\n", err)
+		os.Exit(1)
+	}
+
 	// Initialize and run TUI
-	model := NewModel(s3Client, bucketName)
-	program := tea.NewProgram(model, tea.WithAltScreen())
+	model := NewModel(s3Client, bucketName, settings)
+	program := tea.NewProgram(model, tea.WithAltScreen(), tea.WithOutput(terminal))
 
-	if _, err := program.Run(); err != nil {
+	_, err = program.Run()
+	cleanupOpenFiles()
+	if err != nil {
 		fmt.Printf("Error running TUI: %s\n", err)
 		os.Exit(1)
 	}
@@ -0,0 +1,278 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// manifestEntry is one presigned object of a URL manifest
+type manifestEntry struct {
+	Key  string `json:"key"`
+	Size int64  `json:"size"`
+	ETag string `json:"etag"`
+	URL  string `json:"url"`
+}
+
+// manifest is a set of presigned GET URLs sharing one expiry
+type manifest struct {
+	Bucket  string          `json:"bucket"`
+	Expires time.Time       `json:"expires"`
+	Objects []manifestEntry `json:"objects"`
+}
+
+type manifestWrittenMsg struct {
+	path    string
+	count   int
+	expires time.Time
+	err     error
+}
+
+// manifestFormat returns the manifest format for a file name: csv, json or txt
+func manifestFormat(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		return "csv"
+	case ".json":
+		return "json"
+	case ".txt":
+		return "txt"
+	}
+	return ""
+}
+
+// parseManifestOptions parses the manifest prompt: an optional expiry, then
+// the file to write ("7d delivery.csv"). The rest of the input after the
+// expiry is the path, so it may contain spaces.
+func parseManifestOptions(input string) (path string, expiry time.Duration, err error) {
+	expiry, path = presignDefaultExpiry, strings.TrimSpace(input)
+	if first, rest, ok := strings.Cut(path, " "); ok {
+		if parsed, err := parseExpiry(first); err == nil {
+			expiry, path = parsed, strings.TrimSpace(rest)
+		}
+	} else if parsed, err := parseExpiry(path); err == nil {
+		expiry, path = parsed, ""
+	}
+	if manifestFormat(path) == "" {
+		return "", 0, fmt.Errorf("give the manifest file after the expiry, ending in .csv, .json or .txt")
+	}
+	return path, expiry, nil
+}
+
+// buildManifest presigns a GET URL for every object of the targets, listing
+// folders (ending in "/") recursively. Folder markers are left out.
+func buildManifest(ctx context.Context, client *S3Client, bucket string, targets []string, expiry time.Duration) (*manifest, error) {
+	var objects []S3Object
+	seen := make(map[string]bool)
+	for _, target := range targets {
+		if !strings.HasSuffix(target, "/") {
+			if seen[target] {
+				continue
+			}
+			info, err := client.HeadObject(ctx, bucket, target)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read '%s': %w", displayKey(target), err)
+			}
+			seen[target] = true
+			objects = append(objects, S3Object{Key: target, Size: info.Size, ETag: info.ETag})
+			continue
+		}
+		listed, err := client.ListAllObjects(ctx, bucket, target)
+		if err != nil {
+			return nil, err
+		}
+		for _, obj := range listed {
+			if !strings.HasSuffix(obj.Key, "/") && !seen[obj.Key] {
+				seen[obj.Key] = true
+				objects = append(objects, obj)
+			}
+		}
+	}
+	if len(objects) == 0 {
+		return nil, fmt.Errorf("no objects to presign")
+	}
+
+	// Every URL is signed at about the same time, so they share the expiry
+	result := &manifest{Bucket: bucket, Expires: time.Now().Add(expiry).UTC().Truncate(time.Second)}
+	for _, obj := range objects {
+		url, err := client.PresignURL(ctx, bucket, obj.Key, "GET", expiry)
+		if err != nil {
+			return nil, err
+		}
+		result.Objects = append(result.Objects, manifestEntry{
+			Key:  obj.Key,
+			Size: obj.Size,
+			ETag: strings.Trim(obj.ETag, `"`),
+			URL:  url,
+		})
+	}
+	return result, nil
+}
+
+// write writes the manifest as CSV with a header row, as JSON, or as plain
+// text with one tab-separated object per line. Keys that hold a tab, a line
+// break or another control character are quoted in plain text, as Go strings.
+func (mf *manifest) write(w io.Writer, format string) error {
+	switch format {
+	case "json":
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(mf)
+	case "csv":
+		cw := csv.NewWriter(w)
+		cw.Write([]string{"key", "size", "etag", "url", "expires"})
+		expires := mf.Expires.Format(time.RFC3339)
+		for _, entry := range mf.Objects {
+			cw.Write([]string{entry.Key, strconv.FormatInt(entry.Size, 10), entry.ETag, entry.URL, expires})
+		}
+		cw.Flush()
+		return cw.Error()
+	case "txt":
+		for _, entry := range mf.Objects {
+			key := entry.Key
+			if strings.IndexFunc(key, unicode.IsControl) >= 0 || strings.HasPrefix(key, `"`) {
+				key = strconv.Quote(key)
+			}
+			if _, err := fmt.Fprintf(w, "%s\t%d\t%s\t%s\n", key, entry.Size, entry.ETag, entry.URL); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return fmt.Errorf("unknown manifest format '%s' (use csv, json or txt)", format)
+}
+
+// writeManifestFile writes the manifest to a local file in the format of its extension
+func writeManifestFile(mf *manifest, path string) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create manifest '%s': %w", path, err)
+	}
+	if err := mf.write(file, manifestFormat(path)); err != nil {
+		file.Close()
+		os.Remove(path)
+		return fmt.Errorf("failed to write manifest '%s': %w", path, err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("failed to write manifest '%s': %w", path, err)
+	}
+	return nil
+}
+
+// defaultManifestName suggests a manifest file name for the targets
+func defaultManifestName(bucket string, targets []string) string {
+	name := bucket
+	if len(targets) == 1 {
+		name = keyName(targets[0])
+	}
+	return "presigned-" + localFileName(name) + ".csv"
+}
+
+// exportManifest presigns every object of the targets and writes the manifest file
+func (m Model) exportManifest(targets []string, path string, expiry time.Duration) tea.Cmd {
+	client, bucket := m.s3Client, m.bucket
+	return tea.Cmd(func() tea.Msg {
+		mf, err := buildManifest(context.Background(), client, bucket, targets, expiry)
+		if err != nil {
+			return manifestWrittenMsg{err: err}
+		}
+		if err := writeManifestFile(mf, path); err != nil {
+			return manifestWrittenMsg{err: err}
+		}
+		return manifestWrittenMsg{path: path, count: len(mf.Objects), expires: mf.Expires}
+	})
+}
+
+// applyManifestWritten reports an exported manifest
+func (m Model) applyManifestWritten(msg manifestWrittenMsg) (tea.Model, tea.Cmd) {
+	m.loading = false
+	if msg.err != nil {
+		m.err = msg.err
+		m.statusMessage = ""
+		return m, nil
+	}
+	m.err = nil
+	m.statusMessage = fmt.Sprintf("✓ Wrote %d presigned URL(s), valid until %s, to '%s'",
+		msg.count, msg.expires.Local().Format("2006-01-02 15:04:05"), msg.path)
+	return m, nil
+}
+
+// runPresignCommand implements "s4 presign": it writes a manifest of presigned
+// URLs for objects and prefixes without starting the TUI
+func runPresignCommand(args []string) error {
+	flags := flag.NewFlagSet("presign", flag.ContinueOnError)
+	expires := flags.String("expires", "1h", "how long the URLs stay valid, e.g. 30m, 12h or 7d")
+	output := flags.String("o", "", "manifest file (.csv, .json or .txt); standard output if not given")
+	format := flags.String("format", "", "manifest format when writing to standard output: csv, json or txt (default csv)")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: s4 presign [options] <bucket-name> <key-or-prefix/>...")
+		fmt.Fprintln(flags.Output(), "\nPresigns a GET URL for every object given, and every object under each")
+		fmt.Fprintln(flags.Output(), "prefix ending in '/', and writes key, size, ETag and URL as a manifest.")
+		fmt.Fprintln(flags.Output(), "\nOptions:")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() < 2 {
+		flags.Usage()
+		return fmt.Errorf("missing bucket name or keys")
+	}
+
+	expiry, err := parseExpiry(*expires)
+	if err != nil {
+		return err
+	}
+	switch *format {
+	case "", "csv", "json", "txt":
+	default:
+		return fmt.Errorf("unknown manifest format '%s' (use csv, json or txt)", *format)
+	}
+	if *output != "" {
+		if manifestFormat(*output) == "" {
+			return fmt.Errorf("manifest file must end in .csv, .json or .txt")
+		}
+		if *format != "" && *format != manifestFormat(*output) {
+			return fmt.Errorf("-format %s does not match the manifest file '%s'", *format, *output)
+		}
+	}
+
+	config, err := LoadS3Config()
+	if err != nil {
+		return fmt.Errorf("no S3 configuration found: %w", err)
+	}
+	client, err := NewS3Client(config)
+	if err != nil {
+		return err
+	}
+
+	bucket := flags.Arg(0)
+	mf, err := buildManifest(context.Background(), client, bucket, flags.Args()[1:], expiry)
+	if err != nil {
+		return err
+	}
+
+	if *output != "" {
+		if err := writeManifestFile(mf, *output); err != nil {
+			return err
+		}
+		fmt.Fprintf(os.Stderr, "Wrote %d presigned URL(s), valid until %s, to %s\n",
+			len(mf.Objects), mf.Expires.Local().Format("2006-01-02 15:04:05"), *output)
+		return nil
+	}
+	if *format == "" {
+		*format = "csv"
+	}
+	return mf.write(os.Stdout, *format)
+}
@@ -0,0 +1,341 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// userMetadataPrefix starts the names of user metadata fields in the editor
+const userMetadataPrefix = "x-amz-meta-"
+
+// metadataChange is one edit made in the metadata editor. An empty value
+// removes the header or user metadata field.
+type metadataChange struct {
+	name     string
+	oldValue string // Value on the object the editor was opened on
+	value    string
+}
+
+// metadataEdit is a set of changes awaiting confirmation
+type metadataEdit struct {
+	keys    []string
+	changes []metadataChange
+}
+
+// metadataLoadedMsg opens the metadata editor on the objects of a bulk edit,
+// showing the headers of the first one
+type metadataLoadedMsg struct {
+	keys []string
+	info *ObjectInfo
+	err  error
+}
+
+// loadMetadataTargets expands folders in the targets and reads the headers of the first object
+func (m Model) loadMetadataTargets(targets []string) tea.Cmd {
+	return tea.Cmd(func() tea.Msg {
+		keys, err := expandKeys(context.Background(), m.s3Client, m.bucket, targets)
+		if err != nil {
+			return metadataLoadedMsg{err: err}
+		}
+		info, err := m.s3Client.HeadObject(context.Background(), m.bucket, keys[0])
+		return metadataLoadedMsg{keys: keys, info: info, err: err}
+	})
+}
+
+// metadataFields lists the editable headers of an object, including empty
+// ones, followed by its user metadata
+func metadataFields(info *ObjectInfo) []infoField {
+	fields := []infoField{
+		{"Content-Type", info.ContentType},
+		{"Content-Encoding", info.ContentEncoding},
+		{"Content-Disposition", info.ContentDisposition},
+		{"Content-Language", info.ContentLanguage},
+		{"Cache-Control", info.CacheControl},
+	}
+	names := make([]string, 0, len(info.Metadata))
+	for name := range info.Metadata {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fields = append(fields, infoField{userMetadataPrefix + name, info.Metadata[name]})
+	}
+	return fields
+}
+
+// metadataChanges compares the edited fields with the original ones
+func metadataChanges(original, edited []infoField) []metadataChange {
+	before := make(map[string]string, len(original))
+	for _, field := range original {
+		before[field.name] = field.value
+	}
+	after := make(map[string]bool, len(edited))
+
+	var changes []metadataChange
+	for _, field := range edited {
+		after[field.name] = true
+		if before[field.name] != field.value {
+			changes = append(changes, metadataChange{name: field.name, oldValue: before[field.name], value: field.value})
+		}
+	}
+	for _, field := range original {
+		if !after[field.name] && field.value != "" {
+			changes = append(changes, metadataChange{name: field.name, oldValue: field.value})
+		}
+	}
+	return changes
+}
+
+// applyMetadataChanges returns a copy of info with the changes made, and
+// whether anything differs from info
+func applyMetadataChanges(info *ObjectInfo, changes []metadataChange) (*ObjectInfo, bool) {
+	updated := *info
+	updated.Metadata = make(map[string]string, len(info.Metadata))
+	for name, value := range info.Metadata {
+		updated.Metadata[name] = value
+	}
+
+	changed := false
+	set := func(field *string, value string) {
+		if *field != value {
+			*field = value
+			changed = true
+		}
+	}
+	for _, change := range changes {
+		switch change.name {
+		case "Content-Type":
+			set(&updated.ContentType, change.value)
+		case "Content-Encoding":
+			set(&updated.ContentEncoding, change.value)
+		case "Content-Disposition":
+			set(&updated.ContentDisposition, change.value)
+		case "Content-Language":
+			set(&updated.ContentLanguage, change.value)
+		case "Cache-Control":
+			set(&updated.CacheControl, change.value)
+		default:
+			name := strings.TrimPrefix(change.name, userMetadataPrefix)
+			current, ok := updated.Metadata[name]
+			switch {
+			case change.value == "" && ok:
+				delete(updated.Metadata, name)
+				changed = true
+			case change.value != "" && current != change.value:
+				updated.Metadata[name] = change.value
+				changed = true
+			}
+		}
+	}
+	return &updated, changed
+}
+
+// applyMetadataLoaded opens the metadata editor
+func (m Model) applyMetadataLoaded(msg metadataLoadedMsg) (tea.Model, tea.Cmd) {
+	m.loading = false
+	if msg.err != nil {
+		m.err = msg.err
+		return m, nil
+	}
+	m.metaKeys = msg.keys
+	m.metaOriginal = metadataFields(msg.info)
+	m.metaFields = append([]infoField{}, m.metaOriginal...)
+	m.metaCursor = 0
+	m.viewMode = ViewMetadata
+	m.err = nil
+	m.statusMessage = ""
+	return m, nil
+}
+
+// setMetadataField sets a field of the metadata editor, adding user metadata fields that are new
+func (m *Model) setMetadataField(name, value string) {
+	for i, field := range m.metaFields {
+		if field.name == name {
+			m.metaFields[i].value = value
+			m.metaCursor = i
+			return
+		}
+	}
+	m.metaFields = append(m.metaFields, infoField{name, value})
+	m.metaCursor = len(m.metaFields) - 1
+}
+
+// addMetadataField parses "name=value" from the prompt into a user metadata field
+func (m *Model) addMetadataField(input string) error {
+	name, value, ok := strings.Cut(input, "=")
+	name = strings.TrimPrefix(strings.ToLower(strings.TrimSpace(name)), userMetadataPrefix)
+	value = strings.TrimSpace(value)
+	if !ok || name == "" || value == "" {
+		return fmt.Errorf("user metadata must be given as name=value")
+	}
+	for _, r := range name {
+		if r <= ' ' || r >= 0x7f || strings.ContainsRune("()<>@,;:\\\"/[]?={}", r) {
+			return fmt.Errorf("invalid character %q in metadata name", r)
+		}
+	}
+	m.setMetadataField(userMetadataPrefix+name, value)
+	return nil
+}
+
+// rewriteMetadata starts a batch job that applies the confirmed changes to every object
+func (m Model) rewriteMetadata(edit *metadataEdit) tea.Cmd {
+	client, bucket := m.s3Client, m.bucket
+	return tea.Cmd(func() tea.Msg {
+		return startBatch(batchJob{
+			title: "Updating metadata",
+			keys:  edit.keys,
+			apply: func(ctx context.Context, key string) error {
+				info, err := client.HeadObject(ctx, bucket, key)
+				if err != nil {
+					return err
+				}
+				if info.SSECustomerAlgorithm != "" {
+					return fmt.Errorf("objects encrypted with a customer key (SSE-C) cannot be rewritten")
+				}
+				updated, changed := applyMetadataChanges(info, edit.changes)
+				if !changed {
+					return nil
+				}
+				return client.ReplaceMetadata(ctx, bucket, key, updated)
+			},
+		})
+	})
+}
+
+// metadataConfirmMessage describes the changes awaiting confirmation as a diff
+func metadataConfirmMessage(edit *metadataEdit) string {
+	var lines []string
+	for _, change := range edit.changes {
+		if change.oldValue != "" {
+			lines = append(lines, diffRemoveStyle.Render(fmt.Sprintf("-%s: %s", change.name, displayKey(change.oldValue))))
+		}
+		if change.value != "" {
+			lines = append(lines, diffAddStyle.Render(fmt.Sprintf("+%s: %s", change.name, displayKey(change.value))))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// updateMetadata handles metadata editor updates
+func (m Model) updateMetadata(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c":
+		return m, tea.Quit
+	case "esc", "q":
+		m.viewMode = ViewBrowser
+		m.metaFields = nil
+		m.metaOriginal = nil
+		m.statusMessage = "Metadata left unchanged"
+	case "up", "k":
+		if m.metaCursor > 0 {
+			m.metaCursor--
+		}
+	case "down", "j":
+		if m.metaCursor < len(m.metaFields)-1 {
+			m.metaCursor++
+		}
+	case "enter", "e":
+		field := m.metaFields[m.metaCursor]
+		m.openPrompt("metadata_value", "Edit Metadata", field.name+" (empty removes it):", field.value)
+	case "a":
+		m.openPrompt("metadata_add", "Add User Metadata", "Name and value (name=value):", "")
+	case "x", "d":
+		// Clear the value under the cursor; user metadata fields go away entirely
+		field := m.metaFields[m.metaCursor]
+		if strings.HasPrefix(field.name, userMetadataPrefix) {
+			m.metaFields = append(m.metaFields[:m.metaCursor:m.metaCursor], m.metaFields[m.metaCursor+1:]...)
+			m.metaCursor = min(m.metaCursor, len(m.metaFields)-1)
+		} else {
+			m.metaFields[m.metaCursor].value = ""
+		}
+	case "u":
+		m.metaFields = append([]infoField{}, m.metaOriginal...)
+		m.metaCursor = min(m.metaCursor, len(m.metaFields)-1)
+		m.statusMessage = "Changes undone"
+	case "s", "ctrl+s":
+		changes := metadataChanges(m.metaOriginal, m.metaFields)
+		if len(changes) == 0 {
+			m.statusMessage = "Nothing changed"
+			return m, nil
+		}
+		m.confirmAction = "metadata_apply"
+		m.confirmTarget = m.metaKeys[0]
+		m.confirmData = &metadataEdit{keys: m.metaKeys, changes: changes}
+		m.viewMode = ViewConfirm
+		m.err = nil
+		m.statusMessage = ""
+	}
+	return m, nil
+}
+
+// viewMetadata renders the metadata editor
+func (m Model) viewMetadata() string {
+	var s strings.Builder
+
+	title := fmt.Sprintf("Metadata: %s", displayKey(keyName(m.metaKeys[0])))
+	if len(m.metaKeys) > 1 {
+		title = fmt.Sprintf("Metadata: %d objects (showing '%s')", len(m.metaKeys), displayKey(keyName(m.metaKeys[0])))
+	}
+	s.WriteString(titleStyle.Render(title))
+	s.WriteString("\n\n")
+
+	original := make(map[string]string, len(m.metaOriginal))
+	for _, field := range m.metaOriginal {
+		original[field.name] = field.value
+	}
+	nameWidth := 0
+	for _, field := range m.metaFields {
+		nameWidth = max(nameWidth, len(field.name))
+	}
+	valueWidth := max(m.width-nameWidth-22, 20)
+
+	var content strings.Builder
+	height := max(m.height-12, 5)
+	start := 0
+	if m.metaCursor >= height {
+		start = m.metaCursor - height + 1
+	}
+	for i := start; i < len(m.metaFields) && i < start+height; i++ {
+		field := m.metaFields[i]
+		cursor := " "
+		if i == m.metaCursor {
+			cursor = ">"
+		}
+		mark := " "
+		if value, ok := original[field.name]; !ok || value != field.value {
+			mark = "*"
+		}
+		value := displayKey(field.value)
+		if value == "" {
+			value = "-"
+		}
+		line := fmt.Sprintf("%s%s %-*s  %s", cursor, mark, nameWidth, displayKey(field.name), truncateString(value, valueWidth))
+		if i == m.metaCursor {
+			line = selectedStyle.Render(line)
+		}
+		content.WriteString(line + "\n")
+	}
+
+	if m.err != nil {
+		content.WriteString("\n" + errorStyle.Render(fmt.Sprintf("Error: %s", m.err.Error())))
+	} else if m.statusMessage != "" {
+		content.WriteString("\n" + successStyle.Render(m.statusMessage))
+	} else if len(m.metaKeys) > 1 {
+		content.WriteString("\n" + helpStyle.Render("Only changed fields (*) are written to each object; the rest keep their own values"))
+	}
+	s.WriteString(previewStyle.Render(strings.TrimSuffix(content.String(), "\n")))
+
+	s.WriteString("\n\n")
+	s.WriteString(helpStyle.Render("↑/k,↓/j: move • enter/e: edit • a: add user metadata • x: remove • u: undo all • s: save • esc: cancel"))
+
+	if m.width > 0 && m.height > 0 {
+		centered := centerStyle.Width(m.width).Render(lipgloss.NewStyle().Align(lipgloss.Left).Render(s.String()))
+		return verticalCenterStyle.Height(m.height).Render(centered)
+	}
+	return s.String()
+}
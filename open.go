@@ -0,0 +1,207 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// openFiles is the temporary directory holding objects opened with external
+// programs. Viewers may keep reading a file after s4 hands it over, so the
+// files are only removed when s4 exits.
+var openFiles struct {
+	sync.Mutex
+	dir string
+}
+
+// Messages for opening objects with external programs
+type openWithMsg struct {
+	key         string
+	contentType string
+	err         error
+}
+
+type openFileReadyMsg struct {
+	key        string
+	path       string
+	command    string
+	background bool
+	err        error
+}
+
+type viewerClosedMsg struct {
+	err error
+}
+
+// objectType returns the media type of an object, guessed from the key name
+// when the stored Content-Type is missing or generic
+func objectType(key, contentType string) string {
+	switch mediaType(contentType) {
+	case "", "application/octet-stream", "binary/octet-stream":
+		if guessed := mime.TypeByExtension(filepath.Ext(keyName(key))); guessed != "" {
+			return mediaType(guessed)
+		}
+	}
+	return mediaType(contentType)
+}
+
+// openHandler picks the command that opens an object: the first configured
+// handler that matches, else $PAGER for text and the desktop opener otherwise
+func (m Model) openHandler(key, contentType string) (string, bool) {
+	for _, handler := range m.settings.OpenHandlers {
+		if matchPattern(handler.Pattern, key, contentType) {
+			return handler.Command, handler.Background
+		}
+	}
+
+	media := mediaType(contentType)
+	if strings.HasPrefix(media, "text/") || strings.HasSuffix(media, "json") || strings.HasSuffix(media, "xml") {
+		if pager := os.Getenv("PAGER"); pager != "" {
+			return pager, false
+		}
+		return "less", false
+	}
+	if runtime.GOOS == "darwin" {
+		return "open", true
+	}
+	return "xdg-open", true
+}
+
+// openWith looks up the Content-Type of an object to suggest a handler for it
+func (m Model) openWith(key string) tea.Cmd {
+	return tea.Cmd(func() tea.Msg {
+		info, err := m.s3Client.HeadObject(context.Background(), m.bucket, key)
+		if err != nil {
+			return openWithMsg{key: key, err: err}
+		}
+		return openWithMsg{key: key, contentType: objectType(key, info.ContentType)}
+	})
+}
+
+// applyOpenWith asks for the command to open an object with, suggesting the configured handler
+func (m Model) applyOpenWith(msg openWithMsg) (tea.Model, tea.Cmd) {
+	m.loading = false
+	if msg.err != nil {
+		m.err = msg.err
+		return m, nil
+	}
+	command, background := m.openHandler(msg.key, msg.contentType)
+	if background {
+		command += " &"
+	}
+	m.openKey = msg.key
+	m.openPrompt("open_with", "Open With",
+		fmt.Sprintf("Command for '%s' (%s); {} is the file, a trailing & keeps s4 running:", displayKey(keyName(msg.key)), msg.contentType), command)
+	return m, nil
+}
+
+// downloadForViewer streams an object to a temporary file for an external program
+func (m Model) downloadForViewer(key, command string) tea.Cmd {
+	command, background := splitBackground(command)
+	return tea.Cmd(func() tea.Msg {
+		path, err := m.downloadOpenFile(key)
+		return openFileReadyMsg{key: key, path: path, command: command, background: background, err: err}
+	})
+}
+
+// downloadOpenFile streams an object into its own folder of the temporary
+// directory, keeping its name so viewers recognise the format
+func (m Model) downloadOpenFile(key string) (string, error) {
+	openFiles.Lock()
+	if openFiles.dir == "" {
+		dir, err := os.MkdirTemp("", "s4-open-*")
+		if err != nil {
+			openFiles.Unlock()
+			return "", fmt.Errorf("failed to create temporary directory: %w", err)
+		}
+		openFiles.dir = dir
+	}
+	root := openFiles.dir
+	openFiles.Unlock()
+
+	dir, err := os.MkdirTemp(root, "")
+	if err != nil {
+		return "", fmt.Errorf("failed to create temporary directory: %w", err)
+	}
+	name := keyName(key)
+	if name == "" || name == "." || name == ".." {
+		name = "object"
+	}
+	path := filepath.Join(dir, filepath.Base(name))
+
+	body, err := m.s3Client.GetObjectStream(context.Background(), m.bucket, key)
+	if err != nil {
+		return "", err
+	}
+	defer body.Close()
+
+	file, err := os.Create(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	defer file.Close()
+	if _, err := io.Copy(file, body); err != nil {
+		return "", fmt.Errorf("failed to download object: %w", err)
+	}
+	return path, nil
+}
+
+// viewerCommand builds the shell command that opens path, substituting it for
+// {} or appending it
+func viewerCommand(command, path string) *exec.Cmd {
+	quoted := "'" + strings.ReplaceAll(path, "'", `'\''`) + "'"
+	if strings.Contains(command, "{}") {
+		command = strings.ReplaceAll(command, "{}", quoted)
+	} else {
+		command += " " + quoted
+	}
+	return exec.Command("sh", "-c", command)
+}
+
+// applyOpenFileReady runs the viewer on the downloaded object, suspending the
+// TUI unless the viewer runs in the background
+func (m Model) applyOpenFileReady(msg openFileReadyMsg) (tea.Model, tea.Cmd) {
+	m.loading = false
+	if msg.err != nil {
+		m.err = msg.err
+		return m, nil
+	}
+
+	cmd := viewerCommand(msg.command, msg.path)
+	if !msg.background {
+		// The viewer needs the terminal itself, not the program's output wrapper
+		cmd.Stdout = os.Stdout
+		return m, tea.ExecProcess(cmd, func(err error) tea.Msg {
+			return viewerClosedMsg{err: err}
+		})
+	}
+
+	// Background viewers must not write over the TUI
+	if err := cmd.Start(); err != nil {
+		m.err = fmt.Errorf("failed to run '%s': %w", msg.command, err)
+		return m, nil
+	}
+	go cmd.Wait()
+	m.err = nil
+	m.statusMessage = fmt.Sprintf("✓ Opened '%s' with %s", displayKey(keyName(msg.key)), msg.command)
+	return m, nil
+}
+
+// cleanupOpenFiles removes the objects downloaded for external programs
+func cleanupOpenFiles() {
+	openFiles.Lock()
+	defer openFiles.Unlock()
+	if openFiles.dir != "" {
+		os.RemoveAll(openFiles.dir)
+		openFiles.dir = ""
+	}
+}
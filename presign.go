@@ -0,0 +1,206 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"rsc.io/qr"
+)
+
+const (
+	// presignDefaultExpiry is used when no expiry is given
+	presignDefaultExpiry = time.Hour
+	// presignMaxExpiry is the longest validity SigV4 allows
+	presignMaxExpiry = 7 * 24 * time.Hour
+	// qrQuietZone is the light border, in modules, scanners need around a QR code
+	qrQuietZone = 2
+)
+
+// presignedMsg carries a generated URL to the share dialog
+type presignedMsg struct {
+	key     string
+	method  string
+	url     string
+	expires time.Time
+	err     error
+}
+
+// parseExpiry parses an expiry such as "90s", "15m", "12h" or "7d"
+func parseExpiry(text string) (time.Duration, error) {
+	var expiry time.Duration
+	if days, ok := strings.CutSuffix(text, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil {
+			return 0, fmt.Errorf("invalid expiry '%s'", text)
+		}
+		expiry = time.Duration(n) * 24 * time.Hour
+	} else {
+		var err error
+		expiry, err = time.ParseDuration(text)
+		if err != nil {
+			return 0, fmt.Errorf("invalid expiry '%s' (use e.g. 30m, 12h or 7d)", text)
+		}
+	}
+	if expiry < time.Second || expiry > presignMaxExpiry {
+		return 0, fmt.Errorf("expiry must be between 1s and 7d")
+	}
+	return expiry, nil
+}
+
+// parsePresignOptions parses the share prompt: an expiry and optionally the
+// method, in any order ("1h", "put 15m"). The default is a GET URL for an hour.
+func parsePresignOptions(input string) (method string, expiry time.Duration, err error) {
+	method, expiry = "GET", presignDefaultExpiry
+	for _, field := range strings.Fields(strings.ToLower(input)) {
+		switch field {
+		case "get", "put":
+			method = strings.ToUpper(field)
+		default:
+			if expiry, err = parseExpiry(field); err != nil {
+				return "", 0, err
+			}
+		}
+	}
+	return method, expiry, nil
+}
+
+// presignObject creates a presigned URL for an object
+func (m Model) presignObject(key, method string, expiry time.Duration) tea.Cmd {
+	return tea.Cmd(func() tea.Msg {
+		expires := time.Now().Add(expiry)
+		url, err := m.s3Client.PresignURL(context.Background(), m.bucket, key, method, expiry)
+		return presignedMsg{key: key, method: method, url: url, expires: expires, err: err}
+	})
+}
+
+// applyPresigned opens the share dialog and copies the URL to the clipboard
+func (m Model) applyPresigned(msg presignedMsg) (tea.Model, tea.Cmd) {
+	m.loading = false
+	if msg.err != nil {
+		m.err = msg.err
+		return m, nil
+	}
+	m.shareKey = msg.key
+	m.shareMethod = msg.method
+	m.shareURL = msg.url
+	m.shareExpires = msg.expires
+	m.shareQR = false
+	m.viewMode = ViewShare
+	m.err = nil
+	m.statusMessage = ""
+	return m, copyCmd("URL", msg.url)
+}
+
+// renderQR draws text as a QR code in half-block characters, two modules per
+// cell vertically. Light modules are the filled ones, so the code scans on
+// the usual dark terminal background.
+func renderQR(text string) (string, error) {
+	code, err := qr.Encode(text, qr.L)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode QR code: %w", err)
+	}
+
+	size := code.Size + 2*qrQuietZone
+	light := func(x, y int) bool {
+		x, y = x-qrQuietZone, y-qrQuietZone
+		if x < 0 || y < 0 || x >= code.Size || y >= code.Size {
+			return true
+		}
+		return !code.Black(x, y)
+	}
+
+	var b strings.Builder
+	for y := 0; y < size; y += 2 {
+		for x := 0; x < size; x++ {
+			top, bottom := light(x, y), y+1 < size && light(x, y+1)
+			switch {
+			case top && bottom:
+				b.WriteString("█")
+			case top:
+				b.WriteString("▀")
+			case bottom:
+				b.WriteString("▄")
+			default:
+				b.WriteString(" ")
+			}
+		}
+		b.WriteString("\n")
+	}
+	return strings.TrimSuffix(b.String(), "\n"), nil
+}
+
+// wrapURL breaks a URL, which has no spaces to wrap at, into lines of width characters
+func wrapURL(url string, width int) string {
+	var lines []string
+	for len(url) > width {
+		lines = append(lines, url[:width])
+		url = url[width:]
+	}
+	return strings.Join(append(lines, url), "\n")
+}
+
+// updateShare handles share dialog updates
+func (m Model) updateShare(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c", "q":
+		return m, tea.Quit
+	case "esc", "enter":
+		m.viewMode = ViewBrowser
+		m.shareURL = ""
+		m.err = nil
+	case "y":
+		return m, copyCmd("URL", m.shareURL)
+	case "Q":
+		m.shareQR = !m.shareQR
+	}
+	return m, nil
+}
+
+// viewShare renders the share dialog with the presigned URL
+func (m Model) viewShare() string {
+	var s strings.Builder
+
+	s.WriteString(titleStyle.Render(fmt.Sprintf("Share: %s", displayKey(keyName(m.shareKey)))))
+	s.WriteString("\n\n")
+
+	validity := time.Until(m.shareExpires).Round(time.Second)
+	s.WriteString(fmt.Sprintf("%s URL, valid until %s (%s)\n\n", m.shareMethod, m.shareExpires.Local().Format("2006-01-02 15:04:05"), validity))
+
+	width := max(m.width-16, 40)
+	if m.shareQR {
+		code, err := renderQR(m.shareURL)
+		switch {
+		case err != nil:
+			s.WriteString(errorStyle.Render(err.Error()))
+		case lipgloss.Width(code) > m.width-4 || lipgloss.Height(code) > m.height-8:
+			s.WriteString(errorStyle.Render(fmt.Sprintf("The terminal is too small for the QR code (it needs %dx%d)", lipgloss.Width(code)+4, lipgloss.Height(code)+8)))
+		default:
+			s.WriteString(code)
+		}
+	} else {
+		s.WriteString(wrapURL(m.shareURL, width))
+		if m.shareMethod == "PUT" {
+			s.WriteString("\n\n" + helpStyle.Render("Upload with: curl -T <file> '<URL>'"))
+		}
+	}
+	s.WriteString("\n\n")
+
+	if m.err != nil {
+		s.WriteString(errorStyle.Render(fmt.Sprintf("Error: %s", m.err.Error())) + "\n\n")
+	} else if m.statusMessage != "" {
+		s.WriteString(successStyle.Render(m.statusMessage) + "\n\n")
+	}
+	s.WriteString(helpStyle.Render("y: copy URL • Q: toggle QR code • esc: close • q: quit"))
+
+	content := lipgloss.NewStyle().Align(lipgloss.Left).Render(s.String())
+	if m.width > 0 && m.height > 0 {
+		centered := centerStyle.Width(m.width).Render(content)
+		return verticalCenterStyle.Height(m.height).Render(centered)
+	}
+	return content
+}
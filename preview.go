@@ -0,0 +1,1015 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+)
+
+const (
+	// previewChunkSize is the number of bytes fetched per ranged read
+	previewChunkSize = 256 * 1024
+	// previewMaxWindow is the most object data kept in memory while previewing
+	previewMaxWindow = 4 * 1024 * 1024
+	// previewFetchMargin is how close (in lines) to the edge of the loaded
+	// window the view may scroll before the next range is fetched
+	previewFetchMargin = 50
+	// previewColumnStep is how many columns h and l scroll long lines sideways
+	previewColumnStep = 10
+	// previewTabWidth is the number of spaces a tab is shown as
+	previewTabWidth = 4
+)
+
+// chunkMode tells how a fetched range is merged into the preview window
+type chunkMode int
+
+const (
+	chunkOpen    chunkMode = iota // First range of a newly opened preview
+	chunkReplace                  // Replace the window (jump to head or tail)
+	chunkAppend                   // Range directly after the window
+	chunkPrepend                  // Range directly before the window
+	chunkJump                     // Replace the window around a search match
+)
+
+type previewChunkMsg struct {
+	key    string
+	size   int64
+	start  int64
+	data   []byte
+	mode   chunkMode
+	eof    bool  // The read reached the end of the decompressed data
+	target int64 // Offset of the line to scroll to for chunkJump
+
+	// Set when the preview is opened
+	contentType string
+	etag        string
+	codec       string
+	storedSize  int64
+	stream      *decompressStream
+	table       *columnarPreview // Schema summary and rows of Parquet and Avro files
+	filter      string           // Previewer whose output is shown instead of the object
+	version     string           // Version shown, "" for the current object
+
+	err error
+}
+
+// previewFileContent opens a preview by reading the size of an object and its first range
+func (m Model) previewFileContent(key string) tea.Cmd {
+	return m.previewVersionContent(key, "")
+}
+
+// previewVersionContent opens a preview of a version of an object ("" for the current one)
+func (m Model) previewVersionContent(key, versionID string) tea.Cmd {
+	return tea.Cmd(func() tea.Msg {
+		info, err := m.s3Client.HeadObjectVersion(context.Background(), m.bucket, key, versionID)
+		if err != nil {
+			return previewChunkMsg{key: key, mode: chunkOpen, err: err}
+		}
+		if info.Size == 0 {
+			return previewChunkMsg{key: key, mode: chunkOpen, eof: true, contentType: info.ContentType, version: versionID}
+		}
+		if previewer := m.previewerFor(key, info.ContentType); previewer != nil {
+			// Configured previewers take precedence over the built-in formats
+			return m.previewFiltered(key, versionID, info, previewer)
+		}
+
+		codec := compressionFromName(key, info.ContentEncoding)
+		if codec == "" {
+			if kind := columnarFormat(key, nil); kind != "" {
+				return m.previewColumnar(key, versionID, info, kind)
+			}
+			end := int64(previewChunkSize)
+			if end > info.Size {
+				end = info.Size
+			}
+			data, err := m.s3Client.GetObjectVersionRange(context.Background(), m.bucket, key, versionID, 0, end)
+			if err != nil {
+				return previewChunkMsg{key: key, mode: chunkOpen, err: err}
+			}
+			if kind := columnarFormat(key, data); kind != "" {
+				return m.previewColumnar(key, versionID, info, kind)
+			}
+			codec = compressionFromMagic(data)
+			if codec == "" {
+				return previewChunkMsg{key: key, size: info.Size, start: 0, data: data, mode: chunkOpen, eof: true, contentType: info.ContentType, etag: info.ETag, storedSize: info.Size, version: versionID}
+			}
+		}
+
+		// Compressed objects are previewed through a decompressing stream
+		stream := newDecompressStream(m.s3Client, m.bucket, key, versionID, codec)
+		data, eof, err := stream.ReadRange(context.Background(), 0, previewChunkSize)
+		if err != nil {
+			stream.Close()
+			return previewChunkMsg{key: key, mode: chunkOpen, err: err}
+		}
+		return previewChunkMsg{
+			key:         key,
+			size:        int64(len(data)),
+			start:       0,
+			data:        data,
+			mode:        chunkOpen,
+			eof:         eof,
+			contentType: info.ContentType,
+			etag:        info.ETag,
+			codec:       codec,
+			storedSize:  info.Size,
+			stream:      stream,
+			version:     versionID,
+		}
+	})
+}
+
+// previewColumnar opens a preview of a Parquet or Avro file: its schema and
+// statistics as text, and its first rows as a table
+func (m Model) previewColumnar(key, versionID string, info *ObjectInfo, kind string) tea.Msg {
+	reader := &rangeReaderAt{client: m.s3Client, bucket: m.bucket, key: key, version: versionID, size: info.Size}
+	table, err := readColumnar(reader, info.Size, kind)
+	if err != nil {
+		return previewChunkMsg{key: key, mode: chunkOpen, err: err}
+	}
+	return previewChunkMsg{
+		key:         key,
+		size:        int64(len(table.summary)),
+		data:        []byte(table.summary),
+		mode:        chunkOpen,
+		eof:         true,
+		contentType: info.ContentType,
+		storedSize:  info.Size,
+		table:       table,
+		version:     versionID,
+	}
+}
+
+// readPreviewRange reads [start, end) of the previewed object, decompressed if
+// it is compressed. eof reports whether the end of the object was reached.
+func (m Model) readPreviewRange(ctx context.Context, key string, start, end int64) ([]byte, bool, error) {
+	if m.previewStream != nil {
+		return m.previewStream.ReadRange(ctx, start, end)
+	}
+	data, err := m.s3Client.GetObjectVersionRange(ctx, m.bucket, key, m.previewVersion, start, end)
+	return data, end >= m.previewSize, err
+}
+
+// growPreviewSize records how far into a compressed object a read reached.
+// The decompressed size is only known once the end has been read.
+func (m *Model) growPreviewSize(end int64, eof bool) {
+	if m.previewSizeKnown {
+		return
+	}
+	if end > m.previewSize {
+		m.previewSize = end
+	}
+	if eof {
+		m.previewSize = end
+		m.previewSizeKnown = true
+	}
+}
+
+// closePreviewStream stops decompressing the previewed object
+func (m *Model) closePreviewStream() {
+	if m.previewStream != nil {
+		m.previewStream.Close()
+		m.previewStream = nil
+	}
+}
+
+// fetchPreviewRange reads [start, end) of the previewed object
+func (m Model) fetchPreviewRange(start, end int64, mode chunkMode) tea.Cmd {
+	key := m.previewFileName
+	size := m.previewSize
+	return tea.Cmd(func() tea.Msg {
+		data, eof, err := m.readPreviewRange(context.Background(), key, start, end)
+		return previewChunkMsg{key: key, size: size, start: start, data: data, mode: mode, eof: eof, err: err}
+	})
+}
+
+// fetchPreviewTail reads the last range of a compressed object whose
+// decompressed size is not known yet, which means decompressing all of it
+func (m Model) fetchPreviewTail() tea.Cmd {
+	key := m.previewFileName
+	return tea.Cmd(func() tea.Msg {
+		data, start, err := m.previewStream.ReadTail(context.Background(), previewChunkSize)
+		return previewChunkMsg{key: key, start: start, data: data, mode: chunkReplace, eof: true, err: err}
+	})
+}
+
+// applyPreviewChunk merges a fetched range into the preview window
+func (m Model) applyPreviewChunk(msg previewChunkMsg) (tea.Model, tea.Cmd) {
+	if msg.mode != chunkOpen && msg.key != m.previewFileName {
+		// Stale range for a preview that has since been closed
+		return m, nil
+	}
+	m.previewFetching = false
+	if msg.err != nil {
+		m.err = msg.err
+		return m, nil
+	}
+	if msg.mode != chunkOpen {
+		m.growPreviewSize(msg.start+int64(len(msg.data)), msg.eof)
+	}
+	if (msg.mode == chunkAppend && msg.start != m.previewEnd()) ||
+		(msg.mode == chunkPrepend && msg.start+int64(len(msg.data)) != m.previewStart) {
+		// The window moved while this range was in flight
+		return m, m.maybeFetchPreview()
+	}
+
+	if msg.mode == chunkOpen {
+		m.previewHex = false
+		m.hexData = nil
+		m.hexStart = 0
+		m.hexOffset = 0
+		m.hexSummary = ""
+		m.hexFetching = false
+		m.previewPretty = false
+		m.previewFollow = false
+		m.previewCol = 0
+		m.previewType = msg.contentType
+		m.previewETag = msg.etag
+		m.previewFilter = msg.filter
+		m.previewVersion = msg.version
+		if m.previewStream != msg.stream {
+			m.closePreviewStream()
+		}
+		m.previewStream = msg.stream
+		m.previewCodec = msg.codec
+		m.previewStored = msg.storedSize
+		m.previewSizeKnown = msg.eof
+
+		// Check if content is text (simple heuristic); the range may end mid-rune
+		m.previewBinary = msg.filter == "" && !utf8.Valid(trimPartialRune(msg.data))
+		if m.previewBinary {
+			// Binary objects are shown as a hex dump
+			m.previewFileName = msg.key
+			m.previewData = nil
+			m.previewSize = msg.size
+			m.previewStart = 0
+			m.previewLines = nil
+			m.previewOffsets = nil
+			m.previewScroll = 0
+			m.hexData = msg.data
+			m.previewWidth = m.calculatePreviewWidth()
+			m.viewMode = ViewPreview
+			m.err = nil
+			return m, m.enterHexView(0)
+		}
+		m.previewFileName = msg.key
+		m.previewSize = msg.size
+		name := decompressedName(msg.key)
+		m.previewLexer = previewLexer(name, msg.contentType)
+		m.previewDelimiter = previewDelimiter(name, msg.contentType)
+		m.tableKind = tableKind(name, msg.contentType, m.previewDelimiter)
+		m.previewTable = m.tableKind != ""
+		m.resetTable()
+		if msg.table != nil {
+			// The summary is shown first, the rows are one key away
+			m.previewLexer = nil
+			m.previewDelimiter = 0
+			m.tableKind = tableColumnar
+			m.tableColumns = msg.table.columns
+			m.tableRows = msg.table.rows
+			m.previewTable = false
+		}
+		if msg.filter != "" {
+			// Previewer output has its own format and colours
+			m.previewLexer = nil
+			m.previewDelimiter = 0
+			m.tableKind = ""
+			m.previewTable = false
+		}
+		m.previewLines = nil
+		m.previewOffsets = nil
+		m.previewLineBase = 0
+		m.previewScroll = 0
+		m.searchMatch = -1
+		m.viewMode = ViewPreview
+		m.err = nil
+	}
+
+	// Remember the top visible line so the view does not jump when the window changes
+	topOffset := int64(-1)
+	if msg.mode == chunkAppend || msg.mode == chunkPrepend {
+		if m.previewScroll < len(m.previewOffsets) {
+			topOffset = m.previewOffsets[m.previewScroll]
+		}
+	}
+	oldOffsets := m.previewOffsets
+	oldBase := m.previewLineBase
+
+	switch msg.mode {
+	case chunkOpen, chunkReplace, chunkJump:
+		m.previewData = msg.data
+		m.previewStart = msg.start
+	case chunkAppend:
+		m.previewData = append(m.previewData, msg.data...)
+		if excess := len(m.previewData) - previewMaxWindow; excess > 0 {
+			// Drop whole lines from the front
+			cut := excess
+			if i := bytes.IndexByte(m.previewData[cut:], '\n'); i >= 0 {
+				cut += i + 1
+			}
+			m.previewData = append([]byte{}, m.previewData[cut:]...)
+			m.previewStart += int64(cut)
+		}
+	case chunkPrepend:
+		m.previewData = append(append([]byte{}, msg.data...), m.previewData...)
+		m.previewStart = msg.start
+		if len(m.previewData) > previewMaxWindow {
+			// Drop whole lines from the back
+			keep := previewMaxWindow
+			if i := bytes.LastIndexByte(m.previewData[:keep], '\n'); i >= 0 {
+				keep = i + 1
+			}
+			m.previewData = m.previewData[:keep]
+		}
+	}
+
+	m.rebuildPreviewLines()
+
+	// Work out the absolute line number of the first line, if it can be known
+	switch {
+	case m.previewStart == 0:
+		m.previewLineBase = 0
+	case msg.mode == chunkReplace || msg.mode == chunkJump || oldBase < 0 || len(oldOffsets) == 0 || len(m.previewOffsets) == 0:
+		m.previewLineBase = -1
+	case m.previewOffsets[0] >= oldOffsets[0]:
+		m.previewLineBase = offsetIndex(oldOffsets, m.previewOffsets[0], oldBase, 1)
+	default:
+		m.previewLineBase = offsetIndex(m.previewOffsets, oldOffsets[0], oldBase, -1)
+	}
+
+	if m.previewTable {
+		m.rebuildTable()
+	}
+
+	if topOffset >= 0 {
+		m.previewScroll = 0
+		for i, offset := range m.previewOffsets {
+			if offset > topOffset {
+				break
+			}
+			m.previewScroll = i
+		}
+	}
+	if msg.mode == chunkReplace && m.previewStart > 0 {
+		// Jumped to the tail
+		m.previewScroll = m.maxPreviewScroll()
+	}
+	if msg.mode == chunkJump {
+		// Scroll to the matching line found by a search
+		for i, offset := range m.previewOffsets {
+			if offset > msg.target {
+				break
+			}
+			m.previewScroll = i
+		}
+		m.clampPreviewScroll()
+		m.searchMatch = msg.target
+		m.statusMessage = m.searchPrompt()
+	}
+	if msg.mode == chunkOpen {
+		m.previewWidth = m.calculatePreviewWidth()
+	}
+	m.clampPreviewScroll()
+
+	return m, m.maybeFetchPreview()
+}
+
+// offsetIndex finds offset in offsets and returns base adjusted by the index
+// found in the given direction, or -1 if the offset is not a line start
+func offsetIndex(offsets []int64, offset int64, base, direction int) int {
+	for i, o := range offsets {
+		if o == offset {
+			return base + direction*i
+		}
+	}
+	return -1
+}
+
+// rebuildPreviewLines splits the preview window into lines. Lines cut off by
+// the edges of the window are left out until the neighbouring range is loaded.
+func (m *Model) rebuildPreviewLines() {
+	m.previewLines = []string{}
+	m.previewOffsets = []int64{}
+	if len(m.previewData) == 0 {
+		return
+	}
+
+	if m.previewPretty && m.previewStart == 0 && int64(len(m.previewData)) == m.previewSize {
+		if m.rebuildPrettyLines() {
+			return
+		}
+	}
+
+	segments := bytes.Split(m.previewData, []byte("\n"))
+	offsets := make([]int64, len(segments))
+	pos := m.previewStart
+	for i, segment := range segments {
+		offsets[i] = pos
+		pos += int64(len(segment)) + 1
+	}
+
+	first, last := 0, len(segments)
+	if m.previewStart > 0 {
+		first = 1
+	}
+	end := m.previewStart + int64(len(m.previewData))
+	if end < m.previewSize || !m.previewSizeKnown || (last-first > 1 && len(segments[last-1]) == 0) {
+		last--
+	}
+
+	for i := first; i < last; i++ {
+		line := strings.TrimSuffix(string(segments[i]), "\r")
+		m.previewLines = append(m.previewLines, strings.ToValidUTF8(line, "�"))
+		m.previewOffsets = append(m.previewOffsets, offsets[i])
+	}
+}
+
+// rebuildPrettyLines splits the re-indented object into lines. Line offsets
+// are spread evenly over the object since they no longer map to source bytes.
+// It returns false, turning pretty-printing off, if the object cannot be parsed.
+func (m *Model) rebuildPrettyLines() bool {
+	pretty, err := prettyPrint(prettyKind(decompressedName(m.previewFileName), m.previewType, m.previewData), m.previewData)
+	if err != nil {
+		m.previewPretty = false
+		m.statusMessage = fmt.Sprintf("Cannot pretty-print: %s", err.Error())
+		return false
+	}
+
+	lines := strings.Split(strings.TrimSuffix(string(pretty), "\n"), "\n")
+	for i, line := range lines {
+		m.previewLines = append(m.previewLines, strings.ToValidUTF8(line, "�"))
+		m.previewOffsets = append(m.previewOffsets, int64(i)*m.previewSize/int64(len(lines)))
+	}
+	return true
+}
+
+// togglePretty switches between the raw and re-indented object. The whole
+// object must be in memory, so it is fetched first if only part is loaded.
+func (m *Model) togglePretty() tea.Cmd {
+	m.statusMessage = ""
+	m.previewScroll = 0
+	if m.previewPretty {
+		m.previewPretty = false
+		m.rebuildPreviewLines()
+		m.previewLineBase = 0
+		if m.previewStart > 0 {
+			m.previewLineBase = -1
+		}
+		m.previewWidth = m.calculatePreviewWidth()
+		return m.maybeFetchPreview()
+	}
+
+	if prettyKind(decompressedName(m.previewFileName), m.previewType, m.previewData) == "" {
+		m.statusMessage = "Pretty-printing supports JSON and XML only"
+		return nil
+	}
+	if !m.previewSizeKnown {
+		m.statusMessage = "Decompressed size not known yet; press G to read to the end first"
+		return nil
+	}
+	if m.previewSize > previewMaxWindow {
+		m.statusMessage = fmt.Sprintf("Too large to pretty-print (over %s)", formatSize(previewMaxWindow))
+		return nil
+	}
+
+	m.previewPretty = true
+	m.previewTable = false
+	if m.previewStart > 0 || m.previewMore() {
+		m.previewFetching = true
+		return m.fetchPreviewRange(0, m.previewSize, chunkReplace)
+	}
+	m.rebuildPreviewLines()
+	m.previewLineBase = 0
+	m.previewWidth = m.calculatePreviewWidth()
+	return nil
+}
+
+// trimPartialRune drops an incomplete UTF-8 sequence from the end of data
+func trimPartialRune(data []byte) []byte {
+	for i := 1; i < utf8.UTFMax && i <= len(data); i++ {
+		if utf8.RuneStart(data[len(data)-i]) {
+			if !utf8.FullRune(data[len(data)-i:]) {
+				return data[:len(data)-i]
+			}
+			break
+		}
+	}
+	return data
+}
+
+// previewVisibleHeight returns the number of preview lines shown at once
+func (m Model) previewVisibleHeight() int {
+	visibleHeight := m.height - 8 // Account for title, borders, help
+	if visibleHeight < 1 {
+		visibleHeight = 10
+	}
+	return visibleHeight
+}
+
+// maxPreviewScroll returns the largest scroll offset within the loaded lines
+func (m Model) maxPreviewScroll() int {
+	lines := len(m.previewLines)
+	if m.previewTable {
+		lines = len(m.tableRows)
+	} else if m.previewWrap {
+		// The last lines must fill the screen once wrapped
+		rows := 0
+		for i := len(m.previewLines) - 1; i >= 0; i-- {
+			rows += m.previewLineRows(m.previewLines[i])
+			if rows > m.previewVisibleHeight() {
+				return i + 1
+			}
+		}
+		return 0
+	}
+	maxScroll := lines - m.previewVisibleHeight()
+	if maxScroll < 0 {
+		maxScroll = 0
+	}
+	return maxScroll
+}
+
+// clampPreviewScroll keeps the scroll offset within the loaded lines
+func (m *Model) clampPreviewScroll() {
+	if m.previewScroll > m.maxPreviewScroll() {
+		m.previewScroll = m.maxPreviewScroll()
+	}
+	if m.previewScroll < 0 {
+		m.previewScroll = 0
+	}
+}
+
+// previewEnd returns the object offset just past the loaded window
+func (m Model) previewEnd() int64 {
+	return m.previewStart + int64(len(m.previewData))
+}
+
+// previewMore reports whether the object continues past the loaded window
+func (m Model) previewMore() bool {
+	return !m.previewSizeKnown || m.previewEnd() < m.previewSize
+}
+
+// clampPreviewEnd limits a range end to the object size, when it is known
+func (m Model) clampPreviewEnd(end int64) int64 {
+	if m.previewSizeKnown && end > m.previewSize {
+		return m.previewSize
+	}
+	return end
+}
+
+// maybeFetchPreview fetches the next or previous range when the view nears the edge of the window
+func (m *Model) maybeFetchPreview() tea.Cmd {
+	if m.previewFetching || m.previewData == nil {
+		return nil
+	}
+
+	visibleHeight := m.previewVisibleHeight()
+	if m.previewMore() && m.previewScroll+visibleHeight+previewFetchMargin >= len(m.previewLines) {
+		m.previewFetching = true
+		return m.fetchPreviewRange(m.previewEnd(), m.clampPreviewEnd(m.previewEnd()+previewChunkSize), chunkAppend)
+	}
+	if m.previewStart > 0 && m.previewScroll < previewFetchMargin {
+		start := m.previewStart - previewChunkSize
+		if start < 0 {
+			start = 0
+		}
+		m.previewFetching = true
+		return m.fetchPreviewRange(start, m.previewStart, chunkPrepend)
+	}
+	return nil
+}
+
+// updatePreview handles preview view updates
+func (m Model) updatePreview(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if m.previewTable && m.tablePicker && !m.previewHex {
+		return m.updateTablePicker(msg)
+	}
+
+	switch msg.String() {
+	case "ctrl+c", "q":
+		return m, tea.Quit
+	case "esc", "backspace", "h", "left":
+		if msg.String() == "esc" && m.searchCancel != nil {
+			// Stop the running search rather than leaving the preview
+			m.cancelSearch()
+			m.statusMessage = "Search cancelled"
+			return m, nil
+		}
+		if m.previewTable && !m.previewHex && (msg.String() == "h" || msg.String() == "left") {
+			return m.updateTablePreview(msg)
+		}
+		if m.previewCol > 0 && !m.previewHex && !m.previewTable && (msg.String() == "h" || msg.String() == "left") {
+			// Scroll long lines back; at the first column h leaves the preview
+			m.previewCol = max(m.previewCol-previewColumnStep, 0)
+			return m, nil
+		}
+		m.viewMode = ViewBrowser
+		if m.previewVersion != "" {
+			m.viewMode = ViewVersions
+		}
+		m.previewVersion = ""
+		m.previewFileName = ""
+		m.previewLines = nil
+		m.previewOffsets = nil
+		m.previewData = nil
+		m.previewScroll = 0
+		m.previewWidth = 0
+		m.previewFetching = false
+		m.previewHex = false
+		m.hexData = nil
+		m.hexSummary = ""
+		m.hexFetching = false
+		m.previewPretty = false
+		m.closePreviewStream()
+		m.previewCodec = ""
+		m.previewLexer = nil
+		m.previewDelimiter = 0
+		m.previewTable = false
+		m.tableKind = ""
+		m.resetTable()
+		m.cancelSearch()
+		m.previewFollow = false
+		m.previewCol = 0
+		m.previewFilter = ""
+		m.statusMessage = ""
+		return m, nil
+	}
+
+	if (msg.String() == "i" || msg.String() == "O") && m.previewVersion != "" {
+		m.err = fmt.Errorf("only available for the current version; old versions can be downloaded from the versions view")
+		return m, nil
+	}
+	if msg.String() == "i" && m.archive == nil {
+		m.loading = true
+		return m, m.inspectObject(m.previewFileName)
+	}
+	if msg.String() == "O" {
+		// Rich formats can be handed to an external viewer from the preview
+		if m.archive != nil {
+			m.err = fmt.Errorf("cannot open files inside archives with external programs")
+			return m, nil
+		}
+		return m, m.openWith(m.previewFileName)
+	}
+	if m.previewHex {
+		return m.updateHexPreview(msg)
+	}
+	if m.previewTable {
+		switch msg.String() {
+		case "t", "l", "right", "0", "$", "c":
+			return m.updateTablePreview(msg)
+		}
+	}
+
+	switch msg.String() {
+	case "x":
+		if m.tableKind == tableColumnar {
+			m.statusMessage = "Hex dump is not available for Parquet and Avro summaries"
+			return m, nil
+		}
+		if m.previewFilter != "" {
+			m.statusMessage = "Hex dump is not available for previewer output"
+			return m, nil
+		}
+		// Hex dump starting at the top visible line
+		var offset int64
+		if m.previewScroll < len(m.previewOffsets) {
+			offset = m.previewOffsets[m.previewScroll]
+		}
+		return m, m.enterHexView(offset)
+	case "p":
+		return m, m.togglePretty()
+	case "t":
+		return m, m.toggleTable()
+	case "w":
+		m.previewWrap = !m.previewWrap
+		m.previewCol = 0
+	case "l", "right":
+		if !m.previewWrap {
+			m.previewCol = min(m.previewCol+previewColumnStep, m.maxPreviewCol())
+		}
+		return m, nil
+	case "0":
+		m.previewCol = 0
+		return m, nil
+	case "$":
+		if !m.previewWrap {
+			m.previewCol = m.maxPreviewCol()
+		}
+		return m, nil
+	case "F":
+		return m, m.toggleFollow()
+	case "/", "?":
+		if m.previewTable {
+			m.statusMessage = "Search is not available in table view; press t for the raw text"
+			return m, nil
+		}
+		action, title := "search_forward", "Search Forward"
+		if msg.String() == "?" {
+			action, title = "search_backward", "Search Backward"
+		}
+		m.openPrompt(action, title, "Regular expression (case-insensitive unless it has capitals):", "")
+		return m, nil
+	case "n":
+		return m.searchNext(false)
+	case "N":
+		return m.searchNext(true)
+	case "up", "k":
+		m.previewScroll--
+	case "down", "j":
+		m.previewScroll++
+	case "pgup", "u":
+		m.previewScroll -= 10
+	case "pgdown", "d":
+		m.previewScroll += 10
+	case "home", "g":
+		if m.previewStart > 0 {
+			// Head of the object is not loaded, fetch it
+			m.previewFetching = true
+			m.previewScroll = 0
+			return m, m.fetchPreviewRange(0, m.clampPreviewEnd(previewChunkSize), chunkReplace)
+		}
+		m.previewScroll = 0
+	case "end", "G":
+		if !m.previewSizeKnown {
+			// The end of a compressed object is only found by decompressing all of it
+			m.previewFetching = true
+			return m, m.fetchPreviewTail()
+		}
+		if m.previewEnd() < m.previewSize {
+			// Tail of the object is not loaded, fetch it like tail(1)
+			start := m.previewSize - previewChunkSize
+			if start < 0 {
+				start = 0
+			}
+			m.previewFetching = true
+			return m, m.fetchPreviewRange(start, m.previewSize, chunkReplace)
+		}
+		m.previewScroll = m.maxPreviewScroll()
+	}
+	m.clampPreviewScroll()
+	return m, m.maybeFetchPreview()
+}
+
+// viewPreview renders the file preview view
+func (m Model) viewPreview() string {
+	var s strings.Builder
+
+	title := fmt.Sprintf("Preview: %s", displayKey(m.previewFileName))
+	if m.previewHex {
+		title += " [hex]"
+	} else if m.previewPretty {
+		title += " [pretty]"
+	} else if m.previewTable {
+		title += " [table]"
+	} else if m.previewWrap {
+		title += " [wrap]"
+	}
+	if m.previewFilter != "" {
+		title += fmt.Sprintf(" [via %s]", m.previewFilter)
+	}
+	if m.previewFollow {
+		title += " [follow]"
+	}
+	if m.previewVersion != "" {
+		title += fmt.Sprintf(" [version %s]", shortVersion(m.previewVersion))
+	}
+	if m.previewCodec != "" {
+		title += " " + m.compressionIndicator()
+	}
+	s.WriteString(titleStyle.Render(title))
+	s.WriteString("\n\n")
+
+	if m.err != nil {
+		s.WriteString(errorStyle.Render(fmt.Sprintf("Error: %s", m.err.Error())))
+	} else if m.previewHex {
+		content := m.viewHexDump()
+		if m.previewSize > 0 {
+			content += "\n" + m.hexPosition()
+		}
+		s.WriteString(previewStyle.Width(m.previewWidth - 8).Render(content))
+	} else if m.previewTable {
+		s.WriteString(previewStyle.Width(m.previewWidth - 8).Render(m.viewTable()))
+	} else {
+		// Calculate visible lines
+		visibleHeight := m.previewVisibleHeight()
+
+		var visibleLines []string
+		totalLines := len(m.previewLines)
+
+		if totalLines == 0 {
+			if m.previewSize == 0 {
+				visibleLines = []string{"[Empty file]"}
+			} else {
+				visibleLines = []string{"[Loading...]"}
+			}
+		} else {
+			start := m.previewScroll
+			end := start + visibleHeight
+			if end > totalLines {
+				end = totalLines
+			}
+			if start < totalLines {
+				visibleLines = m.previewLines[start:end]
+			}
+		}
+
+		// Add line numbers and content; numbers are unknown after jumping to the tail
+		var contentBuilder strings.Builder
+		rows := 0
+		for i, line := range visibleLines {
+			var parts []string
+			if totalLines > 0 {
+				parts = m.previewRows(m.renderPreviewLine(line))
+			} else {
+				parts = []string{line}
+			}
+			if i > 0 && rows+len(parts) > visibleHeight {
+				// Wrapped lines filled the screen
+				visibleLines = visibleLines[:i]
+				break
+			}
+			rows += len(parts)
+
+			prefix := "   ~ │ "
+			if m.previewLineBase >= 0 {
+				prefix = fmt.Sprintf("%4d │ ", m.previewLineBase+m.previewScroll+i+1)
+			}
+			for j, part := range parts {
+				if j > 0 {
+					prefix = "     │ "
+				}
+				contentBuilder.WriteString(prefix + part + "\n")
+			}
+		}
+
+		// Show position in the object
+		if m.previewSize > 0 && (totalLines > visibleHeight || m.previewStart > 0 || m.previewMore()) {
+			contentBuilder.WriteString("\n" + m.previewPosition(len(visibleLines)))
+		}
+
+		if m.statusMessage != "" {
+			contentBuilder.WriteString("\n" + helpStyle.Render(m.statusMessage))
+		}
+
+		// Create a preview style with calculated width
+		previewStyleWithWidth := previewStyle.Width(m.previewWidth - 8) // Account for padding and borders
+		s.WriteString(previewStyleWithWidth.Render(contentBuilder.String()))
+	}
+
+	s.WriteString("\n\n")
+	if m.previewHex {
+		s.WriteString(helpStyle.Render("↑/k,↓/j: scroll • u/d: page up/down • g/G: start/end • :: jump to offset • x: text • O: open with • ←/h/esc: back • q: quit"))
+	} else if m.previewTable {
+		s.WriteString(helpStyle.Render("↑/k,↓/j: scroll • ←/h,→/l: columns • 0/$: first/last column • c: pick columns • t: raw • esc: back • q: quit"))
+	} else {
+		s.WriteString(helpStyle.Render("↑/k,↓/j: scroll • u/d: page • g/G: head/tail • ←/h,→/l,0/$: sideways • w: wrap • F: follow • /,?: search • n/N: next/prev • p: pretty • t: table • x: hex • esc: back • q: quit"))
+	}
+
+	// Center the preview content
+	content := s.String()
+	if m.width > 0 && m.height > 0 {
+		centered := centerStyle.Width(m.width).Render(content)
+		return verticalCenterStyle.Height(m.height).Render(centered)
+	}
+	return content
+}
+
+// previewPosition describes the visible lines and how far into the object they reach
+func (m Model) previewPosition(visibleCount int) string {
+	// Offset just past the last visible line
+	endOffset := m.previewEnd()
+	if next := m.previewScroll + visibleCount; next < len(m.previewOffsets) {
+		endOffset = m.previewOffsets[next]
+	}
+	percent := endOffset * 100 / m.previewSize
+
+	extent := fmt.Sprintf("%d%% of %s", percent, formatSize(m.previewSize))
+	if !m.previewSizeKnown {
+		// Only the decompressed bytes read so far are known
+		extent = fmt.Sprintf("%s of %s+ decompressed", formatSize(endOffset), formatSize(m.previewSize))
+	}
+	position := fmt.Sprintf("[%s]", extent)
+	if m.previewLineBase >= 0 {
+		first := m.previewLineBase + m.previewScroll + 1
+		position = fmt.Sprintf("[Showing lines %d-%d | %s]", first, first+visibleCount-1, extent)
+	}
+	if m.previewFetching {
+		position += " loading..."
+	}
+	return position
+}
+
+// compressionIndicator shows the compression format with the stored and decompressed sizes
+func (m Model) compressionIndicator() string {
+	decompressed := formatSize(m.previewSize)
+	if !m.previewSizeKnown {
+		decompressed += "+"
+	}
+	return fmt.Sprintf("[%s %s → %s]", m.previewCodec, formatSize(m.previewStored), decompressed)
+}
+
+// calculatePreviewWidth calculates the optimal width for the preview window
+func (m Model) calculatePreviewWidth() int {
+	if m.previewHex {
+		// Offset, hex and ASCII columns of the hex dump
+		width := 90
+		if width > m.width-10 && m.width-10 >= 40 {
+			width = m.width - 10
+		}
+		return width
+	}
+	if m.previewTable {
+		// Tables use all the room there is and scroll sideways
+		if m.width-10 < 40 {
+			return 40
+		}
+		return m.width - 10
+	}
+	if len(m.previewLines) == 0 {
+		return 80 // Default width
+	}
+
+	maxLineLength := 0
+	for _, line := range m.previewLines {
+		// Use display width for proper Unicode handling, account for line numbers (4 digits + " │ ")
+		lineLength := ansi.StringWidth(expandTabs(line)) + 7
+		if lineLength > maxLineLength {
+			maxLineLength = lineLength
+		}
+	}
+
+	// Add room for the margin and content padding (8 chars) and the padding inside the box (4)
+	optimalWidth := maxLineLength + 12
+
+	// Limit to terminal width minus some margin
+	maxAllowedWidth := m.width - 10
+	if maxAllowedWidth < 40 {
+		maxAllowedWidth = 40 // Minimum usable width
+	}
+
+	if optimalWidth > maxAllowedWidth {
+		return maxAllowedWidth
+	}
+
+	// Ensure minimum width for readability
+	if optimalWidth < 60 {
+		return 60
+	}
+
+	return optimalWidth
+}
+
+// expandTabs replaces tabs with spaces so lines can be measured and cut
+func expandTabs(line string) string {
+	return strings.ReplaceAll(line, "\t", strings.Repeat(" ", previewTabWidth))
+}
+
+// renderPreviewLine colours a preview line: search matches if it has any,
+// syntax highlighting otherwise
+func (m Model) renderPreviewLine(line string) string {
+	// Tabs are expanded last since TSV highlighting splits on them
+	if marked, ok := m.highlightMatches(line); ok {
+		return expandTabs(marked)
+	}
+	return expandTabs(m.highlightPreviewLine(line))
+}
+
+// previewTextWidth returns the room for line content beside the line numbers
+func (m Model) previewTextWidth() int {
+	return max(m.previewWidth-12-7, 10)
+}
+
+// previewRows splits a rendered line into the screen rows it takes: several
+// when wrapping, otherwise the part scrolled into view
+func (m Model) previewRows(line string) []string {
+	width := m.previewTextWidth()
+	if m.previewWrap {
+		return strings.Split(ansi.Hardwrap(line, width, true), "\n")
+	}
+	return []string{ansi.Cut(line, m.previewCol, m.previewCol+width)}
+}
+
+// previewLineRows returns how many screen rows a line takes
+func (m Model) previewLineRows(line string) int {
+	if !m.previewWrap {
+		return 1
+	}
+	width := m.previewTextWidth()
+	return max((ansi.StringWidth(expandTabs(line))+width-1)/width, 1)
+}
+
+// maxPreviewCol returns how far long lines on screen can be scrolled sideways
+func (m Model) maxPreviewCol() int {
+	widest := 0
+	end := min(m.previewScroll+m.previewVisibleHeight(), len(m.previewLines))
+	for i := m.previewScroll; i < end; i++ {
+		widest = max(widest, ansi.StringWidth(expandTabs(m.previewLines[i])))
+	}
+	return max(widest-m.previewTextWidth(), 0)
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// errOutputLimit stops a previewer once its output reaches the cap
+var errOutputLimit = errors.New("output limit reached")
+
+// cappedBuffer collects output up to a limit and then refuses more, which
+// makes the command see a closed pipe
+type cappedBuffer struct {
+	buf       bytes.Buffer
+	limit     int
+	truncated bool
+}
+
+// Write implements io.Writer
+func (b *cappedBuffer) Write(p []byte) (int, error) {
+	room := b.limit - b.buf.Len()
+	if len(p) > room {
+		b.buf.Write(p[:max(room, 0)])
+		b.truncated = true
+		return max(room, 0), errOutputLimit
+	}
+	return b.buf.Write(p)
+}
+
+// previewerFor returns the first configured previewer for an object, if any
+func (m Model) previewerFor(key, contentType string) *Previewer {
+	contentType = objectType(key, contentType)
+	for i, previewer := range m.settings.Previewers {
+		for _, pattern := range previewer.Patterns {
+			if matchPattern(pattern, key, contentType) {
+				return &m.settings.Previewers[i]
+			}
+		}
+	}
+	return nil
+}
+
+// runPreviewer pipes an object (or a version of it), or its first bytes,
+// through a previewer and returns what the command printed
+func (m Model) runPreviewer(previewer *Previewer, key, versionID string) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), previewer.Timeout)
+	defer cancel()
+
+	body, err := m.s3Client.GetObjectVersionStream(ctx, m.bucket, key, versionID)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+	var input io.Reader = body
+	if previewer.Bytes > 0 {
+		input = io.LimitReader(body, previewer.Bytes)
+	}
+
+	stdout := &cappedBuffer{limit: previewer.MaxOutput}
+	stderr := &cappedBuffer{limit: 4096}
+	cmd := exec.CommandContext(ctx, "sh", "-c", previewer.Command)
+	cmd.Stdin = input
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	// Do not wait for children that keep the pipes open after a kill
+	cmd.WaitDelay = time.Second
+
+	err = cmd.Run()
+	switch {
+	case stdout.truncated:
+		// The command was cut off on purpose
+		output := stdout.buf.Bytes()
+		if i := bytes.LastIndexByte(output, '\n'); i >= 0 {
+			output = output[:i+1]
+		}
+		return append(output, fmt.Sprintf("[output cut off at %s]\n", formatSize(int64(previewer.MaxOutput)))...), nil
+	case ctx.Err() == context.DeadlineExceeded:
+		return nil, fmt.Errorf("previewer '%s' timed out after %s", previewer.Name, previewer.Timeout)
+	case err != nil:
+		if message := strings.TrimSpace(stderr.buf.String()); message != "" {
+			return nil, fmt.Errorf("previewer '%s' failed: %w: %s", previewer.Name, err, message)
+		}
+		return nil, fmt.Errorf("previewer '%s' failed: %w", previewer.Name, err)
+	}
+	return stdout.buf.Bytes(), nil
+}
+
+// previewFiltered opens a preview of a previewer's output instead of the object
+func (m Model) previewFiltered(key, versionID string, info *ObjectInfo, previewer *Previewer) previewChunkMsg {
+	output, err := m.runPreviewer(previewer, key, versionID)
+	if err != nil {
+		return previewChunkMsg{key: key, mode: chunkOpen, err: err}
+	}
+	return previewChunkMsg{
+		key:         key,
+		size:        int64(len(output)),
+		data:        output,
+		mode:        chunkOpen,
+		eof:         true,
+		contentType: info.ContentType,
+		etag:        info.ETag,
+		storedSize:  info.Size,
+		filter:      previewer.Name,
+		version:     versionID,
+	}
+}
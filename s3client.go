@@ -1,15 +1,46 @@
 package main
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"io"
+	"net/http"
+	"net/url"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
 	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/feature/s3/manager"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/aws/smithy-go"
+	smithyhttp "github.com/aws/smithy-go/transport/http"
+	"golang.org/x/sync/errgroup"
+)
+
+// errObjectChanged is returned by conditional writes when the object was
+// modified by someone else since it was read
+var errObjectChanged = errors.New("object was changed by someone else since it was read")
+
+// errObjectArchived is returned by reads of objects in an archive storage
+// class (GLACIER, DEEP_ARCHIVE) that have not been restored
+var errObjectArchived = errors.New("object is archived and must be restored before it can be read (R restores it)")
+
+const (
+	// maxSingleCopySize is the largest source S3 accepts in a single CopyObject call
+	maxSingleCopySize = 5 * 1024 * 1024 * 1024
+	// copyPartSize is the minimum part size used for multipart copies
+	copyPartSize = 512 * 1024 * 1024
+	// maxCopyParts is the S3 limit on the number of parts in a multipart upload
+	maxCopyParts = 10000
+	// copyPartConcurrency is the number of UploadPartCopy requests run in parallel
+	copyPartConcurrency = 8
 )
 
 // S3Client wraps the AWS S3 client with our configuration
@@ -18,12 +49,64 @@ type S3Client struct {
 	config *S3Config
 }
 
-// S3Object represents an S3 object with metadata
+// S3Object represents an S3 object with metadata. Directories are common
+// prefixes and keep their trailing "/" in Key.
 type S3Object struct {
 	Key          string
 	Size         int64
 	LastModified string
 	IsDir        bool
+	ETag         string
+	StorageClass string
+	Deleted      bool // Latest version is a delete marker; only listed when deleted objects are shown
+
+	// Restore state of archived objects
+	RestoreInProgress bool
+	RestoreExpiry     time.Time // When the restored copy is removed; zero if not restored
+}
+
+// ObjectInfo holds the metadata returned by HeadObject
+type ObjectInfo struct {
+	Key                string
+	Size               int64
+	ETag               string
+	ContentType        string
+	ContentEncoding    string
+	ContentDisposition string
+	ContentLanguage    string
+	CacheControl       string
+	StorageClass       string
+	Metadata           map[string]string
+
+	// Details shown by the info panel
+	LastModified          time.Time
+	VersionID             string
+	Expires               string
+	WebsiteRedirect       string
+	ServerSideEncryption  string
+	SSEKMSKeyID           string
+	SSECustomerAlgorithm  string
+	BucketKeyEnabled      bool
+	ObjectLockMode        string
+	ObjectLockRetainUntil time.Time
+	ObjectLockLegalHold   string
+	ReplicationStatus     string
+	Expiration            string // Lifecycle expiry, as returned in x-amz-expiration
+	Restore               string // Glacier restore state, as returned in x-amz-restore
+	ArchiveStatus         string
+	PartsCount            int32
+}
+
+// ObjectVersion is one version of an object, or a delete marker, as listed by ListObjectVersions
+type ObjectVersion struct {
+	Key          string
+	VersionID    string
+	IsLatest     bool
+	DeleteMarker bool
+	Size         int64
+	LastModified time.Time
+	ETag         string
+	StorageClass string
 }
 
 // NewS3Client creates a new S3 client from configuration
@@ -54,38 +137,79 @@ func NewS3Client(cfg *S3Config) (*S3Client, error) {
 // ListObjects lists objects in a bucket with a prefix
 func (c *S3Client) ListObjects(ctx context.Context, bucket, prefix string) ([]S3Object, error) {
 	input := &s3.ListObjectsV2Input{
-		Bucket:    aws.String(bucket),
-		Prefix:    aws.String(prefix),
-		Delimiter: aws.String("/"),
+		Bucket:                   aws.String(bucket),
+		Prefix:                   aws.String(prefix),
+		Delimiter:                aws.String("/"),
+		OptionalObjectAttributes: []types.OptionalObjectAttributes{types.OptionalObjectAttributesRestoreStatus},
 	}
 
 	result, err := c.client.ListObjectsV2(ctx, input)
+	if err != nil && unsupportedRequest(err) {
+		// Some S3-compatible servers reject the restore status request
+		input.OptionalObjectAttributes = nil
+		result, err = c.client.ListObjectsV2(ctx, input)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to list objects: %w", err)
 	}
 
 	var objects []S3Object
 
-	// Add directories (common prefixes)
-	for _, prefix := range result.CommonPrefixes {
-		key := strings.TrimSuffix(*prefix.Prefix, "/")
-		if key != "" {
-			objects = append(objects, S3Object{
-				Key:   key,
-				IsDir: true,
-			})
-		}
+	// Add directories (common prefixes). Prefixes such as "/" or "a//" are
+	// valid folders with an empty name and are kept as well.
+	for _, commonPrefix := range result.CommonPrefixes {
+		objects = append(objects, S3Object{
+			Key:   *commonPrefix.Prefix,
+			IsDir: true,
+		})
 	}
 
 	// Add files
 	for _, obj := range result.Contents {
 		key := *obj.Key
-		if !strings.HasSuffix(key, "/") { // Skip directory markers
-			objects = append(objects, S3Object{
+		if key != prefix { // Skip the marker of the listed folder itself
+			object := S3Object{
 				Key:          key,
 				Size:         *obj.Size,
 				LastModified: obj.LastModified.Format("2006-01-02 15:04:05"),
 				IsDir:        false,
+				ETag:         aws.ToString(obj.ETag),
+				StorageClass: string(obj.StorageClass),
+			}
+			if obj.RestoreStatus != nil {
+				object.RestoreInProgress = aws.ToBool(obj.RestoreStatus.IsRestoreInProgress)
+				object.RestoreExpiry = aws.ToTime(obj.RestoreStatus.RestoreExpiryDate)
+			}
+			objects = append(objects, object)
+		}
+	}
+
+	return objects, nil
+}
+
+// ListAllObjects lists every object under a prefix recursively, following
+// pagination. Directory markers (keys ending in "/") are included as-is.
+func (c *S3Client) ListAllObjects(ctx context.Context, bucket, prefix string) ([]S3Object, error) {
+	input := &s3.ListObjectsV2Input{
+		Bucket: aws.String(bucket),
+		Prefix: aws.String(prefix),
+	}
+
+	var objects []S3Object
+	paginator := s3.NewListObjectsV2Paginator(c.client, input)
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list objects: %w", err)
+		}
+
+		for _, obj := range page.Contents {
+			objects = append(objects, S3Object{
+				Key:          *obj.Key,
+				Size:         aws.ToInt64(obj.Size),
+				LastModified: obj.LastModified.Format("2006-01-02 15:04:05"),
+				ETag:         aws.ToString(obj.ETag),
+				StorageClass: string(obj.StorageClass),
 			})
 		}
 	}
@@ -93,6 +217,57 @@ func (c *S3Client) ListObjects(ctx context.Context, bucket, prefix string) ([]S3
 	return objects, nil
 }
 
+// HeadObject fetches an object's metadata without downloading it
+func (c *S3Client) HeadObject(ctx context.Context, bucket, key string) (*ObjectInfo, error) {
+	return c.HeadObjectVersion(ctx, bucket, key, "")
+}
+
+// HeadObjectVersion returns the metadata of a version of an object ("" for the current one)
+func (c *S3Client) HeadObjectVersion(ctx context.Context, bucket, key, versionID string) (*ObjectInfo, error) {
+	input := &s3.HeadObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+	}
+	if versionID != "" {
+		input.VersionId = aws.String(versionID)
+	}
+
+	result, err := c.client.HeadObject(ctx, input)
+	if err != nil {
+		return nil, fmt.Errorf("failed to head object: %w", err)
+	}
+
+	return &ObjectInfo{
+		Key:                key,
+		Size:               aws.ToInt64(result.ContentLength),
+		ETag:               aws.ToString(result.ETag),
+		ContentType:        aws.ToString(result.ContentType),
+		ContentEncoding:    aws.ToString(result.ContentEncoding),
+		ContentDisposition: aws.ToString(result.ContentDisposition),
+		ContentLanguage:    aws.ToString(result.ContentLanguage),
+		CacheControl:       aws.ToString(result.CacheControl),
+		StorageClass:       string(result.StorageClass),
+		Metadata:           result.Metadata,
+
+		LastModified:          aws.ToTime(result.LastModified),
+		VersionID:             aws.ToString(result.VersionId),
+		Expires:               aws.ToString(result.ExpiresString),
+		WebsiteRedirect:       aws.ToString(result.WebsiteRedirectLocation),
+		ServerSideEncryption:  string(result.ServerSideEncryption),
+		SSEKMSKeyID:           aws.ToString(result.SSEKMSKeyId),
+		SSECustomerAlgorithm:  aws.ToString(result.SSECustomerAlgorithm),
+		BucketKeyEnabled:      aws.ToBool(result.BucketKeyEnabled),
+		ObjectLockMode:        string(result.ObjectLockMode),
+		ObjectLockRetainUntil: aws.ToTime(result.ObjectLockRetainUntilDate),
+		ObjectLockLegalHold:   string(result.ObjectLockLegalHoldStatus),
+		ReplicationStatus:     string(result.ReplicationStatus),
+		Expiration:            aws.ToString(result.Expiration),
+		Restore:               aws.ToString(result.Restore),
+		ArchiveStatus:         string(result.ArchiveStatus),
+		PartsCount:            aws.ToInt32(result.PartsCount),
+	}, nil
+}
+
 // GetObject downloads an object from S3
 func (c *S3Client) GetObject(ctx context.Context, bucket, key string) ([]byte, error) {
 	input := &s3.GetObjectInput{
@@ -102,6 +277,9 @@ func (c *S3Client) GetObject(ctx context.Context, bucket, key string) ([]byte, e
 
 	result, err := c.client.GetObject(ctx, input)
 	if err != nil {
+		if objectArchived(err) {
+			return nil, fmt.Errorf("failed to get object: %w", errObjectArchived)
+		}
 		return nil, fmt.Errorf("failed to get object: %w", err)
 	}
 	defer result.Body.Close()
@@ -114,6 +292,39 @@ func (c *S3Client) GetObject(ctx context.Context, bucket, key string) ([]byte, e
 	return data, nil
 }
 
+// GetObjectRange downloads the bytes [start, end) of an object
+func (c *S3Client) GetObjectRange(ctx context.Context, bucket, key string, start, end int64) ([]byte, error) {
+	return c.GetObjectVersionRange(ctx, bucket, key, "", start, end)
+}
+
+// GetObjectVersionRange downloads the bytes [start, end) of a version of an object ("" for the current one)
+func (c *S3Client) GetObjectVersionRange(ctx context.Context, bucket, key, versionID string, start, end int64) ([]byte, error) {
+	input := &s3.GetObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+		Range:  aws.String(fmt.Sprintf("bytes=%d-%d", start, end-1)),
+	}
+	if versionID != "" {
+		input.VersionId = aws.String(versionID)
+	}
+
+	result, err := c.client.GetObject(ctx, input)
+	if err != nil {
+		if objectArchived(err) {
+			return nil, fmt.Errorf("failed to get object range: %w", errObjectArchived)
+		}
+		return nil, fmt.Errorf("failed to get object range: %w", err)
+	}
+	defer result.Body.Close()
+
+	data, err := io.ReadAll(result.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read object data: %w", err)
+	}
+
+	return data, nil
+}
+
 // PutObject uploads an object to S3
 func (c *S3Client) PutObject(ctx context.Context, bucket, key string, data []byte) error {
 	input := &s3.PutObjectInput{
@@ -130,6 +341,205 @@ func (c *S3Client) PutObject(ctx context.Context, bucket, key string, data []byt
 	return nil
 }
 
+// PutObjectIfMatch replaces an object only if its ETag is still info.ETag,
+// keeping the headers, user metadata, storage class and KMS encryption
+// described by info, and the given tags. Objects encrypted with a customer
+// key (SSE-C) cannot be written without that key and are refused.
+func (c *S3Client) PutObjectIfMatch(ctx context.Context, bucket, key string, data []byte, info *ObjectInfo, tags map[string]string) error {
+	if info.SSECustomerAlgorithm != "" {
+		return fmt.Errorf("failed to put object: objects encrypted with a customer key (SSE-C) cannot be rewritten")
+	}
+
+	input := &s3.PutObjectInput{
+		Bucket:  aws.String(bucket),
+		Key:     aws.String(key),
+		Body:    bytes.NewReader(data),
+		IfMatch: aws.String(info.ETag),
+	}
+	setPutHeaders(input, info, tags)
+	if info.StorageClass != "" {
+		input.StorageClass = types.StorageClass(info.StorageClass)
+	}
+	if info.ServerSideEncryption == string(types.ServerSideEncryptionAwsKms) {
+		// Without these the object would be encrypted with the bucket's default
+		input.ServerSideEncryption = types.ServerSideEncryptionAwsKms
+		input.SSEKMSKeyId = aws.String(info.SSEKMSKeyID)
+		input.BucketKeyEnabled = aws.Bool(info.BucketKeyEnabled)
+	}
+
+	_, err := c.client.PutObject(ctx, input)
+	if err != nil {
+		if conditionFailed(err) {
+			return fmt.Errorf("failed to put object: %w", errObjectChanged)
+		}
+		return fmt.Errorf("failed to put object: %w", err)
+	}
+
+	return nil
+}
+
+// setPutHeaders sets the content headers, user metadata and tags of info on
+// an upload. Storage class and encryption are left to the caller.
+func setPutHeaders(input *s3.PutObjectInput, info *ObjectInfo, tags map[string]string) {
+	input.Metadata = info.Metadata
+	if len(tags) > 0 {
+		input.Tagging = aws.String(encodeTagging(tags))
+	}
+	if info.ContentType != "" {
+		input.ContentType = aws.String(info.ContentType)
+	}
+	if info.ContentEncoding != "" {
+		input.ContentEncoding = aws.String(info.ContentEncoding)
+	}
+	if info.ContentDisposition != "" {
+		input.ContentDisposition = aws.String(info.ContentDisposition)
+	}
+	if info.ContentLanguage != "" {
+		input.ContentLanguage = aws.String(info.ContentLanguage)
+	}
+	if info.CacheControl != "" {
+		input.CacheControl = aws.String(info.CacheControl)
+	}
+	if expires, err := http.ParseTime(info.Expires); err == nil {
+		input.Expires = aws.Time(expires)
+	}
+	if info.WebsiteRedirect != "" {
+		input.WebsiteRedirectLocation = aws.String(info.WebsiteRedirect)
+	}
+}
+
+// ReplaceMetadata rewrites the content headers and user metadata of an object
+// to those in info by copying the object onto itself. Storage class, KMS
+// encryption and tags are kept. The copy fails with errObjectChanged if the
+// object no longer has info.ETag.
+func (c *S3Client) ReplaceMetadata(ctx context.Context, bucket, key string, info *ObjectInfo) error {
+	if info.Size > maxSingleCopySize {
+		// Each part copy is conditional on the ETag, and the headers are set on the new upload
+		return c.multipartCopy(ctx, bucket, key, "", bucket, key, info)
+	}
+
+	input := &s3.CopyObjectInput{
+		Bucket:            aws.String(bucket),
+		Key:               aws.String(key),
+		CopySource:        aws.String(copySource(bucket, key)),
+		CopySourceIfMatch: aws.String(info.ETag),
+		MetadataDirective: types.MetadataDirectiveReplace,
+		Metadata:          info.Metadata,
+	}
+	if info.ContentType != "" {
+		input.ContentType = aws.String(info.ContentType)
+	}
+	if info.ContentEncoding != "" {
+		input.ContentEncoding = aws.String(info.ContentEncoding)
+	}
+	if info.ContentDisposition != "" {
+		input.ContentDisposition = aws.String(info.ContentDisposition)
+	}
+	if info.ContentLanguage != "" {
+		input.ContentLanguage = aws.String(info.ContentLanguage)
+	}
+	if info.CacheControl != "" {
+		input.CacheControl = aws.String(info.CacheControl)
+	}
+	if expires, err := http.ParseTime(info.Expires); err == nil {
+		input.Expires = aws.Time(expires)
+	}
+	if info.WebsiteRedirect != "" {
+		input.WebsiteRedirectLocation = aws.String(info.WebsiteRedirect)
+	}
+	if info.StorageClass != "" {
+		input.StorageClass = types.StorageClass(info.StorageClass)
+	}
+	if info.ServerSideEncryption == string(types.ServerSideEncryptionAwsKms) {
+		// Without these the copy would fall back to the bucket's default encryption
+		input.ServerSideEncryption = types.ServerSideEncryptionAwsKms
+		input.SSEKMSKeyId = aws.String(info.SSEKMSKeyID)
+		input.BucketKeyEnabled = aws.Bool(info.BucketKeyEnabled)
+	}
+
+	_, err := c.client.CopyObject(ctx, input)
+	if err != nil {
+		if conditionFailed(err) {
+			return fmt.Errorf("failed to replace metadata: %w", errObjectChanged)
+		}
+		return fmt.Errorf("failed to replace metadata: %w", err)
+	}
+
+	return nil
+}
+
+// conditionFailed reports whether a conditional request was rejected because
+// the object no longer matches
+func conditionFailed(err error) bool {
+	var apiErr smithy.APIError
+	return errors.As(err, &apiErr) && (apiErr.ErrorCode() == "PreconditionFailed" || apiErr.ErrorCode() == "ConditionalRequestConflict")
+}
+
+// objectArchived reports whether a read was rejected because the object is
+// archived and not restored
+func objectArchived(err error) bool {
+	var apiErr smithy.APIError
+	return errors.As(err, &apiErr) && apiErr.ErrorCode() == "InvalidObjectState"
+}
+
+// accessDenied reports whether the server refused a request for lack of permission
+func accessDenied(err error) bool {
+	var respErr *smithyhttp.ResponseError
+	return errors.As(err, &respErr) && respErr.HTTPStatusCode() == http.StatusForbidden
+}
+
+// objectNotFound reports whether the server answered that an object does not exist
+func objectNotFound(err error) bool {
+	var respErr *smithyhttp.ResponseError
+	return errors.As(err, &respErr) && respErr.HTTPStatusCode() == http.StatusNotFound
+}
+
+// unsupportedRequest reports whether the server rejected a request it does not implement
+func unsupportedRequest(err error) bool {
+	var apiErr smithy.APIError
+	return errors.As(err, &apiErr) && (apiErr.ErrorCode() == "NotImplemented" || apiErr.ErrorCode() == "InvalidArgument")
+}
+
+// ChangeStorageClass moves an object to another storage class by copying it
+// onto itself, keeping its headers, user metadata, encryption and tags
+func (c *S3Client) ChangeStorageClass(ctx context.Context, bucket, key string, info *ObjectInfo, storageClass string) error {
+	changed := *info
+	changed.StorageClass = storageClass
+	if err := c.ReplaceMetadata(ctx, bucket, key, &changed); err != nil {
+		return fmt.Errorf("failed to change storage class: %w", err)
+	}
+	return nil
+}
+
+// RestoreArchivedObject asks S3 to make a temporary readable copy of an
+// archived object, kept for days, retrieved at the given tier. Objects in
+// the archive tiers of INTELLIGENT_TIERING take no days: they move back to
+// the frequent access tier.
+func (c *S3Client) RestoreArchivedObject(ctx context.Context, bucket, key string, days int32, tier string, intelligentTiering bool) error {
+	request := &types.RestoreRequest{
+		GlacierJobParameters: &types.GlacierJobParameters{Tier: types.Tier(tier)},
+	}
+	if !intelligentTiering {
+		request.Days = aws.Int32(days)
+	}
+	input := &s3.RestoreObjectInput{
+		Bucket:         aws.String(bucket),
+		Key:            aws.String(key),
+		RestoreRequest: request,
+	}
+
+	_, err := c.client.RestoreObject(ctx, input)
+	if err != nil {
+		var apiErr smithy.APIError
+		if errors.As(err, &apiErr) && apiErr.ErrorCode() == "RestoreAlreadyInProgress" {
+			return nil
+		}
+		return fmt.Errorf("failed to restore object: %w", err)
+	}
+
+	return nil
+}
+
 // DeleteObject deletes an object from S3
 func (c *S3Client) DeleteObject(ctx context.Context, bucket, key string) error {
 	input := &s3.DeleteObjectInput{
@@ -147,15 +557,27 @@ func (c *S3Client) DeleteObject(ctx context.Context, bucket, key string) error {
 
 // CopyObject copies an object within the same bucket
 func (c *S3Client) CopyObject(ctx context.Context, bucket, sourceKey, destKey string) error {
-	copySource := fmt.Sprintf("%s/%s", bucket, sourceKey)
-	
+	return c.CopyObjectBetween(ctx, bucket, sourceKey, bucket, destKey)
+}
+
+// CopyObjectBetween copies an object server-side, possibly into another bucket
+// on the same endpoint. Sources over 5 GB are copied with multipart UploadPartCopy.
+func (c *S3Client) CopyObjectBetween(ctx context.Context, sourceBucket, sourceKey, destBucket, destKey string) error {
+	info, err := c.HeadObject(ctx, sourceBucket, sourceKey)
+	if err != nil {
+		return fmt.Errorf("failed to copy object: %w", err)
+	}
+	if info.Size > maxSingleCopySize {
+		return c.multipartCopy(ctx, sourceBucket, sourceKey, "", destBucket, destKey, info)
+	}
+
 	input := &s3.CopyObjectInput{
-		Bucket:     aws.String(bucket),
+		Bucket:     aws.String(destBucket),
 		Key:        aws.String(destKey),
-		CopySource: aws.String(copySource),
+		CopySource: aws.String(copySource(sourceBucket, sourceKey)),
 	}
 
-	_, err := c.client.CopyObject(ctx, input)
+	_, err = c.client.CopyObject(ctx, input)
 	if err != nil {
 		return fmt.Errorf("failed to copy object: %w", err)
 	}
@@ -163,6 +585,424 @@ func (c *S3Client) CopyObject(ctx context.Context, bucket, sourceKey, destKey st
 	return nil
 }
 
+// encodeTagging encodes tags as the query string the Tagging header of uploads takes
+func encodeTagging(tags map[string]string) string {
+	values := url.Values{}
+	for key, value := range tags {
+		values.Set(key, value)
+	}
+	return values.Encode()
+}
+
+// multipartCopy copies a large object, or a version of it ("" for the current
+// one), with parallel UploadPartCopy requests, carrying over content headers,
+// Expires, website redirect, user metadata, storage class, KMS encryption and tags
+func (c *S3Client) multipartCopy(ctx context.Context, sourceBucket, sourceKey, sourceVersion, destBucket, destKey string, info *ObjectInfo) error {
+	createInput := &s3.CreateMultipartUploadInput{
+		Bucket:   aws.String(destBucket),
+		Key:      aws.String(destKey),
+		Metadata: info.Metadata,
+	}
+	if info.ContentType != "" {
+		createInput.ContentType = aws.String(info.ContentType)
+	}
+	if info.ContentEncoding != "" {
+		createInput.ContentEncoding = aws.String(info.ContentEncoding)
+	}
+	if info.ContentDisposition != "" {
+		createInput.ContentDisposition = aws.String(info.ContentDisposition)
+	}
+	if info.ContentLanguage != "" {
+		createInput.ContentLanguage = aws.String(info.ContentLanguage)
+	}
+	if info.CacheControl != "" {
+		createInput.CacheControl = aws.String(info.CacheControl)
+	}
+	if expires, err := http.ParseTime(info.Expires); err == nil {
+		createInput.Expires = aws.Time(expires)
+	}
+	if info.WebsiteRedirect != "" {
+		createInput.WebsiteRedirectLocation = aws.String(info.WebsiteRedirect)
+	}
+	if info.StorageClass != "" {
+		createInput.StorageClass = types.StorageClass(info.StorageClass)
+	}
+	if info.ServerSideEncryption == string(types.ServerSideEncryptionAwsKms) {
+		// Without these the copy would fall back to the bucket's default encryption
+		createInput.ServerSideEncryption = types.ServerSideEncryptionAwsKms
+		createInput.SSEKMSKeyId = aws.String(info.SSEKMSKeyID)
+		createInput.BucketKeyEnabled = aws.Bool(info.BucketKeyEnabled)
+	}
+
+	// Servers without tagging support have no tags to carry over
+	tags, err := c.GetObjectVersionTagging(ctx, sourceBucket, sourceKey, sourceVersion)
+	if err != nil && !unsupportedRequest(err) {
+		return fmt.Errorf("failed to read source tags: %w", err)
+	}
+	if len(tags) > 0 {
+		createInput.Tagging = aws.String(encodeTagging(tags))
+	}
+
+	created, err := c.client.CreateMultipartUpload(ctx, createInput)
+	if err != nil {
+		return fmt.Errorf("failed to start multipart copy: %w", err)
+	}
+	uploadID := created.UploadId
+
+	// Part size grows for very large objects so that the upload stays within the part limit
+	partSize := int64(copyPartSize)
+	if minSize := (info.Size + maxCopyParts - 1) / maxCopyParts; minSize > partSize {
+		partSize = minSize
+	}
+	partCount := int((info.Size + partSize - 1) / partSize)
+
+	// The first failed part cancels the others
+	parts := make([]types.CompletedPart, partCount)
+	group, groupCtx := errgroup.WithContext(ctx)
+	group.SetLimit(copyPartConcurrency)
+	for i := 0; i < partCount; i++ {
+		start := int64(i) * partSize
+		end := min(start+partSize, info.Size) - 1
+		partNumber := int32(i + 1)
+		group.Go(func() error {
+			result, err := c.client.UploadPartCopy(groupCtx, &s3.UploadPartCopyInput{
+				Bucket:            aws.String(destBucket),
+				Key:               aws.String(destKey),
+				UploadId:          uploadID,
+				PartNumber:        aws.Int32(partNumber),
+				CopySource:        aws.String(versionCopySource(sourceBucket, sourceKey, sourceVersion)),
+				CopySourceRange:   aws.String(fmt.Sprintf("bytes=%d-%d", start, end)),
+				CopySourceIfMatch: aws.String(info.ETag),
+			})
+			if err != nil {
+				return fmt.Errorf("part %d: %w", partNumber, err)
+			}
+			parts[partNumber-1] = types.CompletedPart{
+				ETag:       result.CopyPartResult.ETag,
+				PartNumber: aws.Int32(partNumber),
+			}
+			return nil
+		})
+	}
+
+	if err := group.Wait(); err != nil {
+		// Aborting frees the parts copied so far; an upload that cannot be
+		// aborted keeps being billed, so that is reported too
+		_, abortErr := c.client.AbortMultipartUpload(context.WithoutCancel(ctx), &s3.AbortMultipartUploadInput{
+			Bucket:   aws.String(destBucket),
+			Key:      aws.String(destKey),
+			UploadId: uploadID,
+		})
+		if abortErr != nil {
+			return fmt.Errorf("failed to copy object: %w (and failed to abort multipart upload %s: %v)", err, aws.ToString(uploadID), abortErr)
+		}
+		return fmt.Errorf("failed to copy object: %w", err)
+	}
+
+	sort.Slice(parts, func(i, j int) bool {
+		return *parts[i].PartNumber < *parts[j].PartNumber
+	})
+
+	_, err = c.client.CompleteMultipartUpload(ctx, &s3.CompleteMultipartUploadInput{
+		Bucket:          aws.String(destBucket),
+		Key:             aws.String(destKey),
+		UploadId:        uploadID,
+		MultipartUpload: &types.CompletedMultipartUpload{Parts: parts},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to complete multipart copy: %w", err)
+	}
+
+	return nil
+}
+
+// GetObjectTagging returns the tags of an object
+func (c *S3Client) GetObjectTagging(ctx context.Context, bucket, key string) (map[string]string, error) {
+	return c.GetObjectVersionTagging(ctx, bucket, key, "")
+}
+
+// GetObjectVersionTagging returns the tags of a version of an object ("" for the current one)
+func (c *S3Client) GetObjectVersionTagging(ctx context.Context, bucket, key, versionID string) (map[string]string, error) {
+	input := &s3.GetObjectTaggingInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+	}
+	if versionID != "" {
+		input.VersionId = aws.String(versionID)
+	}
+
+	result, err := c.client.GetObjectTagging(ctx, input)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get object tags: %w", err)
+	}
+
+	tags := make(map[string]string, len(result.TagSet))
+	for _, tag := range result.TagSet {
+		tags[aws.ToString(tag.Key)] = aws.ToString(tag.Value)
+	}
+	return tags, nil
+}
+
+// PutObjectTagging replaces the tags of an object
+func (c *S3Client) PutObjectTagging(ctx context.Context, bucket, key string, tags map[string]string) error {
+	tagSet := make([]types.Tag, 0, len(tags))
+	for name, value := range tags {
+		tagSet = append(tagSet, types.Tag{Key: aws.String(name), Value: aws.String(value)})
+	}
+	sort.Slice(tagSet, func(i, j int) bool {
+		return *tagSet[i].Key < *tagSet[j].Key
+	})
+
+	_, err := c.client.PutObjectTagging(ctx, &s3.PutObjectTaggingInput{
+		Bucket:  aws.String(bucket),
+		Key:     aws.String(key),
+		Tagging: &types.Tagging{TagSet: tagSet},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to put object tags: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteObjectTagging removes all tags of an object
+func (c *S3Client) DeleteObjectTagging(ctx context.Context, bucket, key string) error {
+	_, err := c.client.DeleteObjectTagging(ctx, &s3.DeleteObjectTaggingInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete object tags: %w", err)
+	}
+
+	return nil
+}
+
+// ListObjectVersions lists the versions and delete markers of every object
+// under a prefix, sorted by key and newest first. At most limit entries are
+// returned; truncated reports whether there were more.
+func (c *S3Client) ListObjectVersions(ctx context.Context, bucket, prefix string, limit int) (versions []ObjectVersion, truncated bool, err error) {
+	input := &s3.ListObjectVersionsInput{
+		Bucket: aws.String(bucket),
+		Prefix: aws.String(prefix),
+	}
+
+	for {
+		page, err := c.client.ListObjectVersions(ctx, input)
+		if err != nil {
+			return nil, false, fmt.Errorf("failed to list object versions: %w", err)
+		}
+		versions = append(versions, pageVersions(page)...)
+
+		if !aws.ToBool(page.IsTruncated) {
+			break
+		}
+		if len(versions) >= limit {
+			truncated = true
+			break
+		}
+		input.KeyMarker = page.NextKeyMarker
+		input.VersionIdMarker = page.NextVersionIdMarker
+	}
+
+	sortVersions(versions)
+	if len(versions) > limit {
+		versions = versions[:limit]
+		truncated = true
+	}
+	return versions, truncated, nil
+}
+
+// ListKeyVersions lists every version and delete marker of a single key,
+// newest first. Keys sharing it as a prefix, such as "a.txt.bak" for "a.txt",
+// are left out.
+func (c *S3Client) ListKeyVersions(ctx context.Context, bucket, key string) ([]ObjectVersion, error) {
+	input := &s3.ListObjectVersionsInput{
+		Bucket: aws.String(bucket),
+		Prefix: aws.String(key),
+	}
+
+	var versions []ObjectVersion
+	for {
+		page, err := c.client.ListObjectVersions(ctx, input)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list object versions: %w", err)
+		}
+		for _, v := range pageVersions(page) {
+			if v.Key == key {
+				versions = append(versions, v)
+			}
+		}
+
+		// Keys are listed in order and the key sorts first under its own
+		// prefix, so once the listing moves past it only longer keys remain
+		if !aws.ToBool(page.IsTruncated) || aws.ToString(page.NextKeyMarker) != key {
+			break
+		}
+		input.KeyMarker = page.NextKeyMarker
+		input.VersionIdMarker = page.NextVersionIdMarker
+	}
+
+	sortVersions(versions)
+	return versions, nil
+}
+
+// pageVersions converts the versions and delete markers of a listing page
+func pageVersions(page *s3.ListObjectVersionsOutput) []ObjectVersion {
+	versions := make([]ObjectVersion, 0, len(page.Versions)+len(page.DeleteMarkers))
+	for _, v := range page.Versions {
+		versions = append(versions, ObjectVersion{
+			Key:          aws.ToString(v.Key),
+			VersionID:    aws.ToString(v.VersionId),
+			IsLatest:     aws.ToBool(v.IsLatest),
+			Size:         aws.ToInt64(v.Size),
+			LastModified: aws.ToTime(v.LastModified),
+			ETag:         aws.ToString(v.ETag),
+			StorageClass: string(v.StorageClass),
+		})
+	}
+	for _, marker := range page.DeleteMarkers {
+		versions = append(versions, ObjectVersion{
+			Key:          aws.ToString(marker.Key),
+			VersionID:    aws.ToString(marker.VersionId),
+			IsLatest:     aws.ToBool(marker.IsLatest),
+			DeleteMarker: true,
+			LastModified: aws.ToTime(marker.LastModified),
+		})
+	}
+	return versions
+}
+
+// sortVersions sorts versions by key, then newest first. Versions and delete
+// markers come in separate lists.
+func sortVersions(versions []ObjectVersion) {
+	sort.SliceStable(versions, func(i, j int) bool {
+		a, b := versions[i], versions[j]
+		if a.Key != b.Key {
+			return a.Key < b.Key
+		}
+		if a.IsLatest != b.IsLatest {
+			return a.IsLatest
+		}
+		return a.LastModified.After(b.LastModified)
+	})
+}
+
+// ListDeletedObjects lists the objects directly under a prefix whose latest
+// version is a delete marker, and the folders holding versions of any object.
+// Folders are returned with Deleted set; callers drop those that still exist.
+func (c *S3Client) ListDeletedObjects(ctx context.Context, bucket, prefix string) ([]S3Object, error) {
+	input := &s3.ListObjectVersionsInput{
+		Bucket:    aws.String(bucket),
+		Prefix:    aws.String(prefix),
+		Delimiter: aws.String("/"),
+	}
+
+	var objects []S3Object
+	for {
+		page, err := c.client.ListObjectVersions(ctx, input)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list deleted objects: %w", err)
+		}
+
+		for _, commonPrefix := range page.CommonPrefixes {
+			objects = append(objects, S3Object{Key: aws.ToString(commonPrefix.Prefix), IsDir: true, Deleted: true})
+		}
+		for _, marker := range page.DeleteMarkers {
+			if !aws.ToBool(marker.IsLatest) || aws.ToString(marker.Key) == prefix {
+				continue
+			}
+			objects = append(objects, S3Object{
+				Key:          aws.ToString(marker.Key),
+				LastModified: aws.ToTime(marker.LastModified).Format("2006-01-02 15:04:05"),
+				Deleted:      true,
+			})
+		}
+
+		if !aws.ToBool(page.IsTruncated) {
+			break
+		}
+		input.KeyMarker = page.NextKeyMarker
+		input.VersionIdMarker = page.NextVersionIdMarker
+	}
+
+	return objects, nil
+}
+
+// RestoreObjectVersion makes an old version of an object the current one by
+// copying it over the object, with its headers, metadata and tags. Versions
+// over 5 GiB are copied with multipart UploadPartCopy.
+func (c *S3Client) RestoreObjectVersion(ctx context.Context, bucket, key, versionID string) error {
+	info, err := c.HeadObjectVersion(ctx, bucket, key, versionID)
+	if err != nil {
+		return fmt.Errorf("failed to restore version: %w", err)
+	}
+	if info.Size > maxSingleCopySize {
+		if err := c.multipartCopy(ctx, bucket, key, versionID, bucket, key, info); err != nil {
+			return fmt.Errorf("failed to restore version: %w", err)
+		}
+		return nil
+	}
+
+	input := &s3.CopyObjectInput{
+		Bucket:     aws.String(bucket),
+		Key:        aws.String(key),
+		CopySource: aws.String(versionCopySource(bucket, key, versionID)),
+	}
+
+	_, err = c.client.CopyObject(ctx, input)
+	if err != nil {
+		return fmt.Errorf("failed to restore version: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteObjectVersion permanently deletes one version of an object, or a
+// delete marker, which brings back the version before it
+func (c *S3Client) DeleteObjectVersion(ctx context.Context, bucket, key, versionID string) error {
+	input := &s3.DeleteObjectInput{
+		Bucket:    aws.String(bucket),
+		Key:       aws.String(key),
+		VersionId: aws.String(versionID),
+	}
+
+	_, err := c.client.DeleteObject(ctx, input)
+	if err != nil {
+		return fmt.Errorf("failed to delete version: %w", err)
+	}
+
+	return nil
+}
+
+// PresignURL creates a URL that lets anyone holding it GET or PUT an object
+// until it expires, without credentials of their own
+func (c *S3Client) PresignURL(ctx context.Context, bucket, key, method string, expires time.Duration) (string, error) {
+	presigner := s3.NewPresignClient(c.client, s3.WithPresignExpires(expires))
+
+	var request *v4.PresignedHTTPRequest
+	var err error
+	switch method {
+	case "GET":
+		request, err = presigner.PresignGetObject(ctx, &s3.GetObjectInput{
+			Bucket: aws.String(bucket),
+			Key:    aws.String(key),
+		})
+	case "PUT":
+		request, err = presigner.PresignPutObject(ctx, &s3.PutObjectInput{
+			Bucket: aws.String(bucket),
+			Key:    aws.String(key),
+		})
+	default:
+		return "", fmt.Errorf("cannot presign %s requests", method)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to presign URL: %w", err)
+	}
+
+	return request.URL, nil
+}
+
 // RenameObject renames an object by copying it to the new key and deleting the old one
 func (c *S3Client) RenameObject(ctx context.Context, bucket, oldKey, newKey string) error {
 	// First, copy the object to the new key
@@ -195,4 +1035,97 @@ func (c *S3Client) HeadBucket(ctx context.Context, bucket string) error {
 	}
 
 	return nil
-}
\ No newline at end of file
+}
+
+// SameEndpoint reports whether both clients talk to the same S3 endpoint,
+// which is what makes a server-side copy between them possible
+func (c *S3Client) SameEndpoint(other *S3Client) bool {
+	return c.config.GetEndpointURL() == other.config.GetEndpointURL()
+}
+
+// SameCredentials reports whether both clients sign requests with the same
+// access key, so each can read what the other can
+func (c *S3Client) SameCredentials(other *S3Client) bool {
+	return c.config.AccessKey == other.config.AccessKey && c.config.SecretKey == other.config.SecretKey
+}
+
+// GetObjectStream opens an object for reading without buffering it in memory
+func (c *S3Client) GetObjectStream(ctx context.Context, bucket, key string) (io.ReadCloser, error) {
+	return c.GetObjectVersionStream(ctx, bucket, key, "")
+}
+
+// GetObjectVersionStream opens a version of an object ("" for the current one) for reading
+func (c *S3Client) GetObjectVersionStream(ctx context.Context, bucket, key, versionID string) (io.ReadCloser, error) {
+	input := &s3.GetObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+	}
+	if versionID != "" {
+		input.VersionId = aws.String(versionID)
+	}
+
+	result, err := c.client.GetObject(ctx, input)
+	if err != nil {
+		if objectArchived(err) {
+			return nil, fmt.Errorf("failed to get object: %w", errObjectArchived)
+		}
+		return nil, fmt.Errorf("failed to get object: %w", err)
+	}
+
+	return result.Body, nil
+}
+
+// TransferObject copies an object from one client/bucket to another. Objects
+// on the same endpoint are copied server-side; otherwise, or when the
+// destination's credentials cannot read the source, the object is streamed
+// through this machine.
+func TransferObject(ctx context.Context, src *S3Client, srcBucket, srcKey string, dst *S3Client, dstBucket, dstKey string) error {
+	if src.SameEndpoint(dst) {
+		err := dst.CopyObjectBetween(ctx, srcBucket, srcKey, dstBucket, dstKey)
+		if err == nil || src.SameCredentials(dst) || !accessDenied(err) {
+			return err
+		}
+	}
+	return streamObject(ctx, src, srcBucket, srcKey, dst, dstBucket, dstKey)
+}
+
+// streamObject uploads an object read from src to dst in parts as it is
+// downloaded, so objects of any size can be transferred. Content headers,
+// user metadata and tags are carried over; storage class and encryption are
+// left to the destination bucket, as classes and KMS keys do not carry across
+// servers.
+func streamObject(ctx context.Context, src *S3Client, srcBucket, srcKey string, dst *S3Client, dstBucket, dstKey string) error {
+	info, err := src.HeadObject(ctx, srcBucket, srcKey)
+	if err != nil {
+		return err
+	}
+	if info.SSECustomerAlgorithm != "" {
+		return fmt.Errorf("objects encrypted with a customer key (SSE-C) cannot be transferred")
+	}
+	tags, err := src.GetObjectTagging(ctx, srcBucket, srcKey)
+	if err != nil && !unsupportedRequest(err) {
+		return err
+	}
+
+	body, err := src.GetObjectStream(ctx, srcBucket, srcKey)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+
+	input := &s3.PutObjectInput{
+		Bucket: aws.String(dstBucket),
+		Key:    aws.String(dstKey),
+		Body:   body,
+	}
+	setPutHeaders(input, info, tags)
+
+	uploader := manager.NewUploader(dst.client, func(u *manager.Uploader) {
+		// Parts grow with the object so it fits in the part limit
+		u.PartSize = max(manager.DefaultUploadPartSize, info.Size/int64(manager.MaxUploadParts)+1)
+	})
+	if _, err := uploader.Upload(ctx, input); err != nil {
+		return fmt.Errorf("failed to upload object: %w", err)
+	}
+	return nil
+}
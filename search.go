@@ -0,0 +1,375 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// searchChunkSize is the number of bytes read per step of a search beyond the loaded window
+const searchChunkSize = 1024 * 1024
+
+var searchMatchStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#000000")).Background(lipgloss.Color("#ffff00"))
+
+// Messages for searches that scan beyond the loaded window
+type searchStartedMsg struct {
+	id      int
+	updates <-chan tea.Msg
+}
+
+type searchProgressMsg struct {
+	id      int
+	scanned int64
+}
+
+type searchDoneMsg struct {
+	id     int
+	offset int64 // Object offset of the start of the matching line
+	found  bool
+	err    error
+}
+
+// compileSearch compiles a search pattern. Patterns without upper case
+// letters match case-insensitively, like smartcase in vim.
+func compileSearch(pattern string) (*regexp.Regexp, error) {
+	if !strings.ContainsFunc(pattern, unicode.IsUpper) {
+		pattern = "(?i)" + pattern
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid search pattern: %w", err)
+	}
+	return re, nil
+}
+
+// startSearch compiles a new pattern and jumps to its first match
+func (m Model) startSearch(pattern string, backward bool) (tea.Model, tea.Cmd) {
+	re, err := compileSearch(pattern)
+	if err != nil {
+		m.err = err
+		return m, nil
+	}
+	m.err = nil
+	m.searchPattern = re
+	m.searchText = pattern
+	m.searchBackward = backward
+	return m.searchNext(false)
+}
+
+// searchNext jumps to the next match in the search direction, or the opposite
+// one if reverse is set. The loaded lines are searched first; if there is no
+// match in them, the rest of the object is scanned in the background.
+func (m Model) searchNext(reverse bool) (tea.Model, tea.Cmd) {
+	if m.searchPattern == nil {
+		return m, nil
+	}
+	m.cancelSearch()
+	backward := m.searchBackward != reverse
+	if len(m.previewLines) == 0 {
+		return m, nil
+	}
+
+	// Search from the current match if it is still on screen, else from the top line
+	cursor := m.previewScroll
+	for i, offset := range m.previewOffsets {
+		if offset == m.searchMatch && i >= m.previewScroll && i < m.previewScroll+m.previewVisibleHeight() {
+			cursor = i
+			break
+		}
+	}
+
+	step := 1
+	if backward {
+		step = -1
+	}
+	for i := cursor + step; i >= 0 && i < len(m.previewLines); i += step {
+		if m.searchPattern.MatchString(m.previewLines[i]) {
+			m.showSearchMatch(i)
+			return m, m.maybeFetchPreview()
+		}
+	}
+
+	// Re-indented text has no object offsets to continue from
+	if m.previewPretty || (backward && m.previewStart == 0) || (!backward && !m.previewMore()) {
+		m.statusMessage = fmt.Sprintf("Pattern not found: %s", m.searchText)
+		return m, nil
+	}
+
+	// Continue from the edge of the loaded lines
+	from := m.previewStart
+	if len(m.previewOffsets) > 0 {
+		from = m.previewOffsets[0]
+		if !backward {
+			from = m.previewOffsets[len(m.previewOffsets)-1]
+		}
+	}
+
+	m.searchID++
+	ctx, cancel := context.WithCancel(context.Background())
+	m.searchCancel = cancel
+	m.statusMessage = "Searching..."
+
+	id := m.searchID
+	re := m.searchPattern
+	search := m
+	return m, tea.Cmd(func() tea.Msg {
+		updates := make(chan tea.Msg, 4)
+		go search.scanObject(ctx, id, re, from, backward, updates)
+		return searchStartedMsg{id: id, updates: updates}
+	})
+}
+
+// showSearchMatch scrolls to a matching line and remembers it as the current match
+func (m *Model) showSearchMatch(line int) {
+	m.previewScroll = line
+	m.clampPreviewScroll()
+	m.searchMatch = m.previewOffsets[line]
+	m.statusMessage = m.searchPrompt()
+}
+
+// searchPrompt shows the current pattern the way it was typed
+func (m Model) searchPrompt() string {
+	if m.searchBackward {
+		return "?" + m.searchText
+	}
+	return "/" + m.searchText
+}
+
+// cancelSearch stops a background scan, if one is running
+func (m *Model) cancelSearch() {
+	if m.searchCancel != nil {
+		m.searchCancel()
+		m.searchCancel = nil
+		m.searchUpdates = nil
+	}
+}
+
+// scanObject reads the object in chunks looking for a line that matches re,
+// forward from offset from (the start of a line) or backward from it
+func (m Model) scanObject(ctx context.Context, id int, re *regexp.Regexp, from int64, backward bool, updates chan<- tea.Msg) {
+	defer close(updates)
+
+	var offset int64
+	var found bool
+	var err error
+	switch {
+	case !backward:
+		offset, found, err = m.scanForward(ctx, id, re, from, updates)
+	case m.previewStream != nil:
+		// Compressed data cannot be read backward cheaply, so scan up to from
+		// and keep the last match
+		offset, found, err = m.scanForwardLast(ctx, id, re, from, updates)
+	default:
+		offset, found, err = m.scanBackward(ctx, id, re, from, updates)
+	}
+	select {
+	case updates <- searchDoneMsg{id: id, offset: offset, found: found, err: err}:
+	case <-ctx.Done():
+	}
+}
+
+// matchLines tests the complete lines of data, which starts at object offset
+// start, and returns the offsets of the matching lines
+func matchLines(re *regexp.Regexp, data []byte, start int64) []int64 {
+	var matches []int64
+	for len(data) > 0 {
+		line := data
+		next := len(data)
+		if i := bytes.IndexByte(data, '\n'); i >= 0 {
+			line = data[:i]
+			next = i + 1
+		}
+		if re.Match(bytes.TrimSuffix(line, []byte("\r"))) {
+			matches = append(matches, start)
+		}
+		data = data[next:]
+		start += int64(next)
+	}
+	return matches
+}
+
+// scanForward returns the first line after the line at from that matches
+func (m Model) scanForward(ctx context.Context, id int, re *regexp.Regexp, from int64, updates chan<- tea.Msg) (int64, bool, error) {
+	var carry []byte
+	carryStart := from
+	pos := from
+	for {
+		data, eof, err := m.readPreviewRange(ctx, m.previewFileName, pos, m.clampPreviewEnd(pos+searchChunkSize))
+		if err != nil {
+			return 0, false, err
+		}
+		pos += int64(len(data))
+		buf := append(carry, data...)
+
+		// Keep the trailing partial line for the next chunk
+		complete := buf
+		if !eof {
+			cut := bytes.LastIndexByte(buf, '\n') + 1
+			complete, carry = buf[:cut], append([]byte{}, buf[cut:]...)
+		}
+		for _, match := range matchLines(re, complete, carryStart) {
+			if match > from {
+				return match, true, nil
+			}
+		}
+		carryStart += int64(len(complete))
+
+		if eof || len(data) == 0 || (m.previewSizeKnown && pos >= m.previewSize) {
+			return 0, false, nil
+		}
+		select {
+		case updates <- searchProgressMsg{id: id, scanned: pos - from}:
+		case <-ctx.Done():
+			return 0, false, ctx.Err()
+		}
+	}
+}
+
+// scanBackward returns the last line before the line at from that matches,
+// reading chunks from the end toward the start of the object
+func (m Model) scanBackward(ctx context.Context, id int, re *regexp.Regexp, from int64, updates chan<- tea.Msg) (int64, bool, error) {
+	var carry []byte // Complete bytes of the line cut off at the start of the previous chunk
+	end := from
+	for end > 0 {
+		start := end - searchChunkSize
+		if start < 0 {
+			start = 0
+		}
+		data, _, err := m.readPreviewRange(ctx, m.previewFileName, start, end)
+		if err != nil {
+			return 0, false, err
+		}
+		buf := append(append([]byte{}, data...), carry...)
+
+		// The first line is incomplete unless the chunk starts the object
+		lineStart := start
+		complete := buf
+		if start > 0 {
+			cut := bytes.IndexByte(buf, '\n') + 1
+			if cut == 0 {
+				carry = buf
+				end = start
+				continue
+			}
+			complete, carry = buf[cut:], buf[:cut]
+			lineStart += int64(cut)
+		}
+		matches := matchLines(re, complete, lineStart)
+		for i := len(matches) - 1; i >= 0; i-- {
+			if matches[i] < from {
+				return matches[i], true, nil
+			}
+		}
+
+		end = start
+		select {
+		case updates <- searchProgressMsg{id: id, scanned: from - end}:
+		case <-ctx.Done():
+			return 0, false, ctx.Err()
+		}
+	}
+	return 0, false, nil
+}
+
+// scanForwardLast returns the last line before the line at from that matches,
+// reading the object forward from its start
+func (m Model) scanForwardLast(ctx context.Context, id int, re *regexp.Regexp, from int64, updates chan<- tea.Msg) (int64, bool, error) {
+	var carry []byte
+	var carryStart, pos, last int64
+	found := false
+	for pos < from {
+		data, eof, err := m.readPreviewRange(ctx, m.previewFileName, pos, min(pos+searchChunkSize, from))
+		if err != nil {
+			return 0, false, err
+		}
+		pos += int64(len(data))
+		buf := append(carry, data...)
+		cut := bytes.LastIndexByte(buf, '\n') + 1
+		if eof || pos >= from {
+			cut = len(buf)
+		}
+		for _, match := range matchLines(re, buf[:cut], carryStart) {
+			if match < from {
+				last, found = match, true
+			}
+		}
+		carry = append([]byte{}, buf[cut:]...)
+		carryStart += int64(cut)
+
+		if eof || len(data) == 0 {
+			break
+		}
+		select {
+		case updates <- searchProgressMsg{id: id, scanned: pos}:
+		case <-ctx.Done():
+			return 0, false, ctx.Err()
+		}
+	}
+	return last, found, nil
+}
+
+// fetchPreviewJump loads a window around offset and scrolls to the line starting there
+func (m Model) fetchPreviewJump(offset int64) tea.Cmd {
+	// Start before the line so it is not cut off as a partial first line
+	start := offset - previewChunkSize/2
+	if start < 0 {
+		start = 0
+	}
+	key := m.previewFileName
+	end := m.clampPreviewEnd(start + previewChunkSize)
+	return tea.Cmd(func() tea.Msg {
+		data, eof, err := m.readPreviewRange(context.Background(), key, start, end)
+		return previewChunkMsg{key: key, start: start, data: data, mode: chunkJump, target: offset, eof: eof, err: err}
+	})
+}
+
+// applySearchDone shows the result of a background scan
+func (m Model) applySearchDone(msg searchDoneMsg) (tea.Model, tea.Cmd) {
+	if msg.id != m.searchID || m.viewMode != ViewPreview {
+		return m, nil
+	}
+	m.searchCancel = nil
+	m.searchUpdates = nil
+	if msg.err != nil {
+		m.err = msg.err
+		m.statusMessage = ""
+		return m, nil
+	}
+	if !msg.found {
+		m.statusMessage = fmt.Sprintf("Pattern not found: %s", m.searchText)
+		return m, nil
+	}
+	m.statusMessage = ""
+	m.previewFetching = true
+	return m, m.fetchPreviewJump(msg.offset)
+}
+
+// highlightMatches marks the search matches in a line
+func (m Model) highlightMatches(line string) (string, bool) {
+	if m.searchPattern == nil {
+		return line, false
+	}
+	matches := m.searchPattern.FindAllStringIndex(line, -1)
+	if len(matches) == 0 {
+		return line, false
+	}
+
+	var b strings.Builder
+	last := 0
+	for _, match := range matches {
+		if match[0] == match[1] {
+			continue
+		}
+		b.WriteString(line[last:match[0]])
+		b.WriteString(searchMatchStyle.Render(line[match[0]:match[1]]))
+		last = match[1]
+	}
+	b.WriteString(line[last:])
+	return b.String(), true
+}
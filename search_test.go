@@ -0,0 +1,32 @@
+package main
+
+import (
+	"regexp"
+	"slices"
+	"testing"
+)
+
+func TestMatchLines(t *testing.T) {
+	tests := []struct {
+		name    string
+		pattern string
+		data    string
+		start   int64
+		want    []int64
+	}{
+		{"offsets from start", "err", "ok\nerror here\nfine\nerr\n", 100, []int64{103, 119}},
+		{"no match", "missing", "one\ntwo\n", 0, nil},
+		{"empty data", ".", "", 0, nil},
+		{"last line without newline", "bad", "a\nbad", 10, []int64{12}},
+		{"carriage returns trimmed", "end$", "the end\r\nnext\r\n", 0, []int64{0}},
+		{"empty lines", "^$", "\n\nx\n", 0, []int64{0, 1}},
+		{"match does not span lines", "a.b", "a\nb\n", 0, nil},
+		{"every line", "", "x\ny\nz", 5, []int64{5, 7, 9}},
+	}
+	for _, tt := range tests {
+		got := matchLines(regexp.MustCompile(tt.pattern), []byte(tt.data), tt.start)
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("matchLines(%s) = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
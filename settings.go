@@ -0,0 +1,154 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gopkg.in/ini.v1"
+)
+
+// Settings holds the s4 options parsed from .s4cfg. All of them are optional,
+// so a missing file gives the defaults.
+type Settings struct {
+	OpenHandlers []OpenHandler
+	Previewers   []Previewer
+}
+
+// OpenHandler is an external program that opens objects matching Pattern
+type OpenHandler struct {
+	Pattern    string // Content-Type glob ("image/*") or key name glob ("*.pdf")
+	Command    string // Shell command; {} is replaced by the downloaded file
+	Background bool   // Keep the TUI running instead of suspending it (command ends in "&")
+}
+
+// Previewer is an external filter whose output is shown instead of the
+// object when previewing objects that match one of its patterns
+type Previewer struct {
+	Name      string
+	Patterns  []string      // Content-Type or key name globs, as for OpenHandler
+	Command   string        // Shell command reading the object on stdin
+	Bytes     int64         // Only the first Bytes bytes are piped in, 0 for all
+	Timeout   time.Duration // The command is killed after this long
+	MaxOutput int           // Output beyond this many bytes is cut off
+}
+
+// Defaults for previewer options
+const (
+	previewerTimeout   = 10 * time.Second
+	previewerMaxOutput = previewMaxWindow
+)
+
+// LoadSettings loads the s4 options from .s4cfg, looked up in the same places as .s3cfg
+func LoadSettings() (*Settings, error) {
+	configPaths := []string{
+		".s4cfg",
+		filepath.Join(os.Getenv("HOME"), ".s4cfg"),
+		"/etc/s4cfg",
+	}
+
+	settings := &Settings{}
+	var configPath string
+	for _, p := range configPaths {
+		if _, err := os.Stat(p); err == nil {
+			configPath = p
+			break
+		}
+	}
+	if configPath == "" {
+		return settings, nil
+	}
+
+	// Commands may contain ";" and "#", which are not comments here
+	cfg, err := ini.LoadSources(ini.LoadOptions{IgnoreInlineComment: true}, configPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load %s: %w", configPath, err)
+	}
+
+	for _, key := range cfg.Section("open").Keys() {
+		handler, err := parseOpenHandler(key.Name(), key.String())
+		if err != nil {
+			return nil, fmt.Errorf("invalid handler in %s: %w", configPath, err)
+		}
+		settings.OpenHandlers = append(settings.OpenHandlers, handler)
+	}
+
+	for _, section := range cfg.Sections() {
+		name, ok := strings.CutPrefix(section.Name(), "previewer ")
+		if !ok {
+			continue
+		}
+		previewer, err := parsePreviewer(strings.TrimSpace(name), section)
+		if err != nil {
+			return nil, fmt.Errorf("invalid previewer in %s: %w", configPath, err)
+		}
+		settings.Previewers = append(settings.Previewers, previewer)
+	}
+	return settings, nil
+}
+
+// parsePreviewer parses a [previewer <name>] section
+func parsePreviewer(name string, section *ini.Section) (Previewer, error) {
+	previewer := Previewer{
+		Name:      name,
+		Command:   strings.TrimSpace(section.Key("command").String()),
+		Bytes:     section.Key("bytes").MustInt64(0),
+		Timeout:   section.Key("timeout").MustDuration(previewerTimeout),
+		MaxOutput: section.Key("max_output").MustInt(previewerMaxOutput),
+	}
+	for _, pattern := range strings.Split(section.Key("match").String(), ",") {
+		pattern = strings.ToLower(strings.TrimSpace(pattern))
+		if pattern == "" {
+			continue
+		}
+		if _, err := path.Match(pattern, ""); err != nil {
+			return Previewer{}, fmt.Errorf("'%s': bad pattern '%s': %w", name, pattern, err)
+		}
+		previewer.Patterns = append(previewer.Patterns, pattern)
+	}
+
+	switch {
+	case len(previewer.Patterns) == 0:
+		return Previewer{}, fmt.Errorf("'%s': no match patterns", name)
+	case previewer.Command == "":
+		return Previewer{}, fmt.Errorf("'%s': no command", name)
+	case previewer.Bytes < 0 || previewer.Timeout <= 0 || previewer.MaxOutput <= 0:
+		return Previewer{}, fmt.Errorf("'%s': bytes, timeout and max_output must be positive", name)
+	}
+	return previewer, nil
+}
+
+// parseOpenHandler parses a "pattern = command" line of the [open] section
+func parseOpenHandler(pattern, command string) (OpenHandler, error) {
+	if _, err := path.Match(pattern, ""); err != nil {
+		return OpenHandler{}, fmt.Errorf("bad pattern '%s': %w", pattern, err)
+	}
+	command, background := splitBackground(command)
+	if command == "" {
+		return OpenHandler{}, fmt.Errorf("no command for '%s'", pattern)
+	}
+	return OpenHandler{Pattern: strings.ToLower(pattern), Command: command, Background: background}, nil
+}
+
+// splitBackground removes a trailing "&", which runs a command in the background
+func splitBackground(command string) (string, bool) {
+	command = strings.TrimSpace(command)
+	if trimmed, ok := strings.CutSuffix(command, "&"); ok && !strings.HasSuffix(trimmed, "&") {
+		return strings.TrimSpace(trimmed), true
+	}
+	return command, false
+}
+
+// matchPattern matches a Content-Type glob against the media type, or a key
+// glob against the key name, both case-insensitively
+func matchPattern(pattern, key, contentType string) bool {
+	if strings.Contains(pattern, "/") {
+		ok, _ := path.Match(pattern, mediaType(contentType))
+		return ok
+	}
+	ok, _ := path.Match(pattern, strings.ToLower(keyName(key)))
+	return ok
+}
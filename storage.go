@@ -0,0 +1,260 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// storageClassWidth is the width of the storage class column of the browser
+const storageClassWidth = 19
+
+// storageClasses are the classes objects can be moved to
+var storageClasses = []string{
+	"STANDARD",
+	"STANDARD_IA",
+	"ONEZONE_IA",
+	"INTELLIGENT_TIERING",
+	"GLACIER_IR",
+	"GLACIER",
+	"DEEP_ARCHIVE",
+}
+
+// restoreTiers are the retrieval tiers of archive restores, fastest first
+var restoreTiers = []string{"Expedited", "Standard", "Bulk"}
+
+// archivedStyle marks archived objects, which cannot be read until restored
+var archivedStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#5f87d7"))
+
+// storageChange is a storage class change awaiting confirmation
+type storageChange struct {
+	targets []string
+	class   string
+}
+
+// archiveRestore is a restore from the archive awaiting confirmation
+type archiveRestore struct {
+	targets []string
+	days    int
+	tier    string
+}
+
+// archivedClass reports whether objects of a storage class must be restored before they can be read
+func archivedClass(class string) bool {
+	return class == "GLACIER" || class == "DEEP_ARCHIVE"
+}
+
+// storageClassLabel shortens a storage class for the browser column
+func storageClassLabel(class string) string {
+	switch class {
+	case "", "STANDARD":
+		// Listings of some servers leave out the default class
+		return "Standard"
+	case "STANDARD_IA":
+		return "Standard-IA"
+	case "ONEZONE_IA":
+		return "OneZone-IA"
+	case "INTELLIGENT_TIERING":
+		return "Int-Tiering"
+	case "GLACIER_IR":
+		return "Glacier-IR"
+	case "GLACIER":
+		return "Glacier"
+	case "DEEP_ARCHIVE":
+		return "Deep"
+	case "REDUCED_REDUNDANCY":
+		return "RRS"
+	}
+	return class
+}
+
+// storageColumn describes the storage class of a file for the browser, with
+// the restore state of archived files: "Glacier restoring" while a restore
+// runs, "Deep until 06-21" while a restored copy is readable
+func storageColumn(obj S3Object) string {
+	label := storageClassLabel(obj.StorageClass)
+	switch {
+	case obj.RestoreInProgress:
+		label += " restoring"
+	case !obj.RestoreExpiry.IsZero():
+		label += " until " + obj.RestoreExpiry.Local().Format("01-02")
+	}
+	return truncateString(label, storageClassWidth)
+}
+
+// archivedReadError explains why an archived file cannot be read, or returns
+// nil if it can
+func archivedReadError(obj S3Object) error {
+	if obj.IsDir || obj.Deleted || !archivedClass(obj.StorageClass) || !obj.RestoreExpiry.IsZero() {
+		return nil
+	}
+	name := displayKey(keyName(obj.Key))
+	if obj.RestoreInProgress {
+		return fmt.Errorf("'%s' is being restored from %s; it can be read once the restore finishes", name, obj.StorageClass)
+	}
+	return fmt.Errorf("'%s' is archived in %s and must be restored before it can be read; R restores it", name, obj.StorageClass)
+}
+
+// parseRestoreHeader parses the x-amz-restore header of an object:
+// ongoing-request="false", expiry-date="Fri, 21 Dec 2012 00:00:00 GMT"
+func parseRestoreHeader(header string) (inProgress bool, expiry time.Time) {
+	for _, part := range strings.Split(header, `",`) {
+		name, value, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if !ok {
+			continue
+		}
+		value = strings.Trim(value, `"`)
+		switch name {
+		case "ongoing-request":
+			inProgress = value == "true"
+		case "expiry-date":
+			expiry, _ = http.ParseTime(value)
+		}
+	}
+	return inProgress, expiry
+}
+
+// describeRestore turns the x-amz-restore header into text for the info panel
+func describeRestore(header string) string {
+	if header == "" {
+		return ""
+	}
+	inProgress, expiry := parseRestoreHeader(header)
+	switch {
+	case inProgress:
+		return "in progress"
+	case !expiry.IsZero():
+		return "restored copy available until " + expiry.Local().Format("2006-01-02 15:04:05")
+	}
+	return header
+}
+
+// parseStorageClass accepts a storage class in any case, with "-" for "_"
+func parseStorageClass(input string) (string, error) {
+	class := strings.ReplaceAll(strings.ToUpper(input), "-", "_")
+	for _, known := range storageClasses {
+		if class == known {
+			return class, nil
+		}
+	}
+	return "", fmt.Errorf("unknown storage class '%s' (use one of %s)", input, strings.Join(storageClasses, ", "))
+}
+
+// parseRestoreOptions parses the restore prompt: the days to keep the restored
+// copy and the retrieval tier, in any order ("7 bulk")
+func parseRestoreOptions(input string) (days int, tier string, err error) {
+	days, tier = 7, "Standard"
+	for _, field := range strings.Fields(input) {
+		if n, err := strconv.Atoi(field); err == nil {
+			if n < 1 {
+				return 0, "", fmt.Errorf("days must be at least 1")
+			}
+			days = n
+			continue
+		}
+		known := false
+		for _, t := range restoreTiers {
+			if strings.EqualFold(field, t) {
+				tier, known = t, true
+			}
+		}
+		if !known {
+			return 0, "", fmt.Errorf("unknown retrieval tier '%s' (use %s)", field, strings.Join(restoreTiers, ", "))
+		}
+	}
+	return days, tier, nil
+}
+
+// changeStorageClass moves every object of the targets to another storage
+// class, expanding folders first
+func (m Model) changeStorageClass(change *storageChange) tea.Cmd {
+	client, bucket := m.s3Client, m.bucket
+	return tea.Cmd(func() tea.Msg {
+		keys, err := expandKeys(context.Background(), client, bucket, change.targets)
+		if err != nil {
+			return transferStartedMsg{err: err}
+		}
+		return startBatch(batchJob{
+			title: "Moving to " + change.class,
+			keys:  keys,
+			apply: func(ctx context.Context, key string) error {
+				info, err := client.HeadObject(ctx, bucket, key)
+				if err != nil {
+					return err
+				}
+				current := info.StorageClass
+				if current == "" {
+					current = "STANDARD"
+				}
+				if current == change.class {
+					return nil
+				}
+				if info.SSECustomerAlgorithm != "" {
+					return fmt.Errorf("objects encrypted with a customer key (SSE-C) cannot be rewritten")
+				}
+				if _, expiry := parseRestoreHeader(info.Restore); archivedClass(current) && expiry.IsZero() {
+					return fmt.Errorf("archived in %s; restore it first", current)
+				}
+				if info.ArchiveStatus != "" {
+					return fmt.Errorf("in the %s tier of INTELLIGENT_TIERING; restore it first", info.ArchiveStatus)
+				}
+				return client.ChangeStorageClass(ctx, bucket, key, info, change.class)
+			},
+		})
+	})
+}
+
+// restoreArchived starts a restore of every archived object of the targets,
+// expanding folders first. Objects that are not archived are skipped.
+func (m Model) restoreArchived(restore *archiveRestore) tea.Cmd {
+	client, bucket := m.s3Client, m.bucket
+	return tea.Cmd(func() tea.Msg {
+		keys, err := expandKeys(context.Background(), client, bucket, restore.targets)
+		if err != nil {
+			return transferStartedMsg{err: err}
+		}
+		return startBatch(batchJob{
+			title: fmt.Sprintf("Restoring from archive (%s)", restore.tier),
+			keys:  keys,
+			apply: func(ctx context.Context, key string) error {
+				info, err := client.HeadObject(ctx, bucket, key)
+				if err != nil {
+					return err
+				}
+				intelligentTiering := info.StorageClass == "INTELLIGENT_TIERING" && info.ArchiveStatus != ""
+				if !archivedClass(info.StorageClass) && !intelligentTiering {
+					return nil
+				}
+				return client.RestoreArchivedObject(ctx, bucket, key, int32(restore.days), restore.tier, intelligentTiering)
+			},
+		})
+	})
+}
+
+// storageClassConfirmMessage describes a storage class change awaiting confirmation
+func storageClassConfirmMessage(change *storageChange) string {
+	target := fmt.Sprintf("%d selected item(s)", len(change.targets))
+	if len(change.targets) == 1 {
+		target = fmt.Sprintf("'%s'", displayKey(keyName(change.targets[0])))
+	}
+	message := fmt.Sprintf("Move %s to %s?\n\nFolders include every object below them. Objects are copied onto themselves,\nwhich creates a new version in versioned buckets.", target, change.class)
+	if archivedClass(change.class) {
+		message += fmt.Sprintf("\n\nObjects in %s cannot be read until they are restored, and are billed\nfor a minimum storage duration.", change.class)
+	}
+	return message
+}
+
+// restoreConfirmMessage describes a restore from the archive awaiting confirmation
+func restoreConfirmMessage(restore *archiveRestore) string {
+	target := fmt.Sprintf("%d selected item(s)", len(restore.targets))
+	if len(restore.targets) == 1 {
+		target = fmt.Sprintf("'%s'", displayKey(keyName(restore.targets[0])))
+	}
+	return fmt.Sprintf("Restore %s from the archive for %d day(s), with %s retrieval?\n\nFolders include every object below them; objects that are not archived are skipped.\nRestores take minutes (Expedited) to hours (Standard, Bulk) and retrieval is billed.", target, restore.days, restore.tier)
+}
@@ -0,0 +1,422 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+const (
+	// tableMaxCellWidth caps the width of a table column
+	tableMaxCellWidth = 40
+	// tableSeparator is drawn between table columns
+	tableSeparator = " │ "
+)
+
+// Kinds of objects the table view can show
+const (
+	tableCSV   = "csv"
+	tableJSONL = "jsonl"
+	// tableColumnar rows are read once from a Parquet or Avro file
+	tableColumnar = "columnar"
+)
+
+var tableHeaderStyle = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("#5f87ff"))
+
+// tableKind returns the table format of an object, or "" if it is not tabular
+func tableKind(key, contentType string, delimiter rune) string {
+	if delimiter != 0 {
+		return tableCSV
+	}
+	switch strings.ToLower(filepath.Ext(keyName(key))) {
+	case ".jsonl", ".ndjson":
+		return tableJSONL
+	}
+	switch mediaType(contentType) {
+	case "application/x-ndjson", "application/jsonl", "application/x-jsonlines":
+		return tableJSONL
+	}
+	return ""
+}
+
+// parseDelimitedLine splits one CSV or TSV line into fields. Quoted fields
+// spanning several lines are not joined, since lines are parsed on their own.
+func parseDelimitedLine(line string, delimiter rune) []string {
+	reader := csv.NewReader(strings.NewReader(line))
+	reader.Comma = delimiter
+	reader.LazyQuotes = true
+	reader.FieldsPerRecord = -1
+	record, err := reader.Read()
+	if err != nil {
+		return []string{line}
+	}
+	return record
+}
+
+// flattenJSON turns nested objects into dotted field names ("a.b.c"). Arrays
+// are kept as compact JSON, since their length differs from row to row.
+func flattenJSON(prefix string, value interface{}, fields map[string]string) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if len(v) == 0 && prefix != "" {
+			fields[prefix] = "{}"
+		}
+		for key, child := range v {
+			name := key
+			if prefix != "" {
+				name = prefix + "." + key
+			}
+			flattenJSON(name, child, fields)
+		}
+	case []interface{}:
+		data, _ := json.Marshal(v)
+		fields[prefix] = string(data)
+	case string:
+		fields[prefix] = v
+	case nil:
+		fields[prefix] = "null"
+	default:
+		fields[prefix] = fmt.Sprint(v)
+	}
+}
+
+// parseJSONLine flattens one JSON Lines record, returning its fields in name order
+func parseJSONLine(line string) ([]string, map[string]string, bool) {
+	decoder := json.NewDecoder(strings.NewReader(line))
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return nil, nil, false
+	}
+
+	fields := make(map[string]string)
+	if _, ok := value.(map[string]interface{}); ok {
+		flattenJSON("", value, fields)
+	} else {
+		// Scalars and arrays fill a single column
+		data, _ := json.Marshal(value)
+		fields["value"] = string(bytes.TrimSpace(data))
+	}
+
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names, fields, true
+}
+
+// rebuildTable parses the loaded preview lines into table rows. The header of
+// a CSV file is its first line; JSON Lines columns are collected from the
+// records as they are loaded, in order of first appearance.
+func (m *Model) rebuildTable() {
+	if m.tableKind == tableColumnar {
+		m.updateTableWidths()
+		return
+	}
+
+	m.tableRows = make([][]string, len(m.previewLines))
+	columnIndex := make(map[string]int, len(m.tableColumns))
+	for i, name := range m.tableColumns {
+		columnIndex[name] = i
+	}
+
+	for i, line := range m.previewLines {
+		switch m.tableKind {
+		case tableCSV:
+			fields := parseDelimitedLine(line, m.previewDelimiter)
+			if m.previewLineBase == 0 && i == 0 {
+				// Header row, kept as the sticky column names
+				m.tableColumns = fields
+				continue
+			}
+			m.tableRows[i] = fields
+		case tableJSONL:
+			if strings.TrimSpace(line) == "" {
+				continue
+			}
+			names, fields, ok := parseJSONLine(line)
+			if !ok {
+				names, fields = []string{"(invalid)"}, map[string]string{"(invalid)": line}
+			}
+			for _, name := range names {
+				if _, exists := columnIndex[name]; !exists {
+					columnIndex[name] = len(m.tableColumns)
+					m.tableColumns = append(m.tableColumns, name)
+				}
+			}
+			row := make([]string, len(m.tableColumns))
+			for name, value := range fields {
+				row[columnIndex[name]] = value
+			}
+			m.tableRows[i] = row
+		}
+	}
+
+	// Files whose header has not been loaded get numbered columns
+	count := len(m.tableColumns)
+	for _, row := range m.tableRows {
+		if len(row) > count {
+			count = len(row)
+		}
+	}
+	for len(m.tableColumns) < count {
+		m.tableColumns = append(m.tableColumns, fmt.Sprintf("#%d", len(m.tableColumns)+1))
+	}
+
+	m.updateTableWidths()
+}
+
+// updateTableWidths fits the column widths to the names and cells. Widths only
+// grow, so columns do not shift while scrolling.
+func (m *Model) updateTableWidths() {
+	for len(m.tableWidths) < len(m.tableColumns) {
+		m.tableWidths = append(m.tableWidths, 1)
+	}
+	for i, name := range m.tableColumns {
+		m.tableWidths[i] = max(m.tableWidths[i], min(utf8.RuneCountInString(name), tableMaxCellWidth))
+	}
+	for _, row := range m.tableRows {
+		for i, cell := range row {
+			m.tableWidths[i] = max(m.tableWidths[i], min(utf8.RuneCountInString(displayKey(cell)), tableMaxCellWidth))
+		}
+	}
+	if m.tableCol >= len(m.tableColumns) {
+		m.tableCol = max(len(m.tableColumns)-1, 0)
+	}
+}
+
+// toggleTable switches between the table and the raw lines
+func (m *Model) toggleTable() tea.Cmd {
+	if m.tableKind == "" {
+		m.statusMessage = "Table view supports CSV, TSV and JSON Lines only"
+		return nil
+	}
+
+	var cmd tea.Cmd
+	if !m.previewTable && m.previewPretty {
+		// The table is built from the raw lines
+		cmd = m.togglePretty()
+	}
+	m.previewTable = !m.previewTable
+	m.tablePicker = false
+	m.statusMessage = ""
+	if m.previewTable {
+		m.rebuildTable()
+	}
+	m.previewWidth = m.calculatePreviewWidth()
+	m.clampPreviewScroll()
+	return cmd
+}
+
+// visibleColumns returns the indexes of the columns that are not hidden
+func (m Model) visibleColumns() []int {
+	var columns []int
+	for i, name := range m.tableColumns {
+		if !m.tableHidden[name] {
+			columns = append(columns, i)
+		}
+	}
+	return columns
+}
+
+// updateTablePreview handles the keys of the table view
+func (m Model) updateTablePreview(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	columns := m.visibleColumns()
+	// Position of the first shown column among the visible ones
+	first := sort.SearchInts(columns, m.tableCol)
+
+	switch msg.String() {
+	case "t":
+		return m, m.toggleTable()
+	case "h", "left":
+		if first > 0 {
+			m.tableCol = columns[first-1]
+		}
+	case "l", "right":
+		if first+1 < len(columns) {
+			m.tableCol = columns[first+1]
+		}
+	case "0":
+		m.tableCol = 0
+	case "$":
+		if len(columns) > 0 {
+			m.tableCol = columns[len(columns)-1]
+		}
+	case "c":
+		m.tablePicker = true
+		m.tablePickerCursor = 0
+	}
+	return m, nil
+}
+
+// updateTablePicker handles the keys of the column picker
+func (m Model) updateTablePicker(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c":
+		return m, tea.Quit
+	case "esc", "enter", "c":
+		m.tablePicker = false
+	case "up", "k":
+		if m.tablePickerCursor > 0 {
+			m.tablePickerCursor--
+		}
+	case "down", "j":
+		if m.tablePickerCursor < len(m.tableColumns)-1 {
+			m.tablePickerCursor++
+		}
+	case " ", "x":
+		if m.tablePickerCursor < len(m.tableColumns) {
+			name := m.tableColumns[m.tablePickerCursor]
+			if m.tableHidden == nil {
+				m.tableHidden = make(map[string]bool)
+			}
+			m.tableHidden[name] = !m.tableHidden[name]
+		}
+	case "a":
+		// Show all columns again
+		m.tableHidden = nil
+	}
+	return m, nil
+}
+
+// viewTable renders the visible rows as aligned columns under a sticky header
+func (m Model) viewTable() string {
+	if m.tablePicker {
+		return m.viewTablePicker()
+	}
+
+	// Columns from the first shown one that fit in the preview width
+	available := m.previewWidth - 12
+	var shown []int
+	used := 0
+	for _, i := range m.visibleColumns() {
+		if i < m.tableCol {
+			continue
+		}
+		width := m.tableWidths[i] + len(tableSeparator)
+		if len(shown) > 0 && used+width > available {
+			break
+		}
+		shown = append(shown, i)
+		used += width
+	}
+
+	renderRow := func(cells []string, style *lipgloss.Style) string {
+		parts := make([]string, 0, len(shown))
+		for _, i := range shown {
+			cell := ""
+			if i < len(cells) {
+				cell = displayKey(cells[i])
+			}
+			cell = padRight(truncateString(cell, m.tableWidths[i]), m.tableWidths[i])
+			if style != nil {
+				cell = style.Render(cell)
+			}
+			parts = append(parts, cell)
+		}
+		return strings.Join(parts, tableSeparator)
+	}
+
+	var b strings.Builder
+	b.WriteString(renderRow(m.tableColumns, &tableHeaderStyle))
+	b.WriteString("\n")
+	b.WriteString(helpStyle.Render(strings.Repeat("─", max(used-len(tableSeparator), 1))))
+	b.WriteString("\n")
+
+	rows := 0
+	height := m.previewVisibleHeight() - 2
+	line := m.previewScroll
+	for ; line < len(m.tableRows) && rows < height; line++ {
+		if m.tableRows[line] == nil {
+			continue
+		}
+		b.WriteString(renderRow(m.tableRows[line], nil))
+		b.WriteString("\n")
+		rows++
+	}
+	if len(m.tableRows) == 0 {
+		if m.tableKind == tableColumnar {
+			b.WriteString("[No rows]\n")
+		} else {
+			b.WriteString("[Loading...]\n")
+		}
+	}
+
+	visible := m.visibleColumns()
+	firstShown := 0
+	if len(shown) > 0 {
+		firstShown = indexOf(visible, shown[0])
+	}
+	status := fmt.Sprintf("[Columns %d-%d of %d", firstShown+1, firstShown+len(shown), len(visible))
+	if hidden := len(m.tableColumns) - len(visible); hidden > 0 {
+		status += fmt.Sprintf(", %d hidden", hidden)
+	}
+	status += "]"
+	if m.tableKind == tableColumnar {
+		status += fmt.Sprintf(" [Rows %d-%d of the first %d]", min(m.previewScroll+1, len(m.tableRows)), line, len(m.tableRows))
+	} else if m.previewSize > 0 {
+		status += " " + m.previewPosition(line-m.previewScroll)
+	}
+	b.WriteString("\n" + status)
+	return b.String()
+}
+
+// indexOf returns the position of value in values, or 0 if it is missing
+func indexOf(values []int, value int) int {
+	for i, v := range values {
+		if v == value {
+			return i
+		}
+	}
+	return 0
+}
+
+// viewTablePicker lists the columns with their visibility
+func (m Model) viewTablePicker() string {
+	var b strings.Builder
+	b.WriteString(tableHeaderStyle.Render("Columns"))
+	b.WriteString("\n\n")
+
+	height := m.previewVisibleHeight() - 4
+	start := 0
+	if m.tablePickerCursor >= height {
+		start = m.tablePickerCursor - height + 1
+	}
+	for i := start; i < len(m.tableColumns) && i < start+height; i++ {
+		cursor := " "
+		if i == m.tablePickerCursor {
+			cursor = ">"
+		}
+		check := "[x]"
+		if m.tableHidden[m.tableColumns[i]] {
+			check = "[ ]"
+		}
+		line := fmt.Sprintf("%s %s %s", cursor, check, displayKey(m.tableColumns[i]))
+		if i == m.tablePickerCursor {
+			line = selectedStyle.Render(line)
+		}
+		b.WriteString(line + "\n")
+	}
+	b.WriteString("\n" + helpStyle.Render("space: show/hide • a: show all • enter/esc: done"))
+	return b.String()
+}
+
+// resetTable forgets the columns of the previous table
+func (m *Model) resetTable() {
+	m.tableColumns = nil
+	m.tableRows = nil
+	m.tableWidths = nil
+	m.tableCol = 0
+	m.tableHidden = nil
+	m.tablePicker = false
+	m.tablePickerCursor = 0
+}
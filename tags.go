@@ -0,0 +1,358 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+const (
+	// maxObjectTags is the S3 limit on the number of tags per object
+	maxObjectTags = 10
+	// maxTagKeyLength and maxTagValueLength are the S3 limits, in characters
+	maxTagKeyLength   = 128
+	maxTagValueLength = 256
+)
+
+// tagOp adds a tag to, or removes one from, every object of a bulk operation
+type tagOp struct {
+	remove bool
+	key    string
+	value  string
+}
+
+// tagBulk is a bulk tag operation awaiting confirmation
+type tagBulk struct {
+	targets []string
+	op      tagOp
+}
+
+// Messages for the tag editor
+type tagsLoadedMsg struct {
+	key  string
+	tags map[string]string
+	err  error
+}
+
+type tagsSavedMsg struct {
+	key   string
+	count int
+	err   error
+}
+
+// loadTags reads the tags of an object for the tag editor
+func (m Model) loadTags(key string) tea.Cmd {
+	return tea.Cmd(func() tea.Msg {
+		tags, err := m.s3Client.GetObjectTagging(context.Background(), m.bucket, key)
+		return tagsLoadedMsg{key: key, tags: tags, err: err}
+	})
+}
+
+// tagFields lists tags sorted by key
+func tagFields(tags map[string]string) []infoField {
+	names := make([]string, 0, len(tags))
+	for name := range tags {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	fields := make([]infoField, 0, len(names))
+	for _, name := range names {
+		fields = append(fields, infoField{name, tags[name]})
+	}
+	return fields
+}
+
+// validateTag checks a tag against the S3 length limits
+func validateTag(key, value string) error {
+	if key == "" {
+		return fmt.Errorf("tag key cannot be empty")
+	}
+	if utf8.RuneCountInString(key) > maxTagKeyLength {
+		return fmt.Errorf("tag key is longer than %d characters", maxTagKeyLength)
+	}
+	if utf8.RuneCountInString(value) > maxTagValueLength {
+		return fmt.Errorf("tag value is longer than %d characters", maxTagValueLength)
+	}
+	return nil
+}
+
+// parseTagOp parses "key=value" (or "+key=value") to add a tag and "-key" to remove one
+func parseTagOp(input string) (tagOp, error) {
+	if key, ok := strings.CutPrefix(input, "-"); ok {
+		key = strings.TrimSpace(key)
+		if key == "" {
+			return tagOp{}, fmt.Errorf("tag key cannot be empty")
+		}
+		return tagOp{remove: true, key: key}, nil
+	}
+	key, value, ok := strings.Cut(strings.TrimPrefix(input, "+"), "=")
+	if !ok {
+		return tagOp{}, fmt.Errorf("give a tag to add as key=value, or one to remove as -key")
+	}
+	key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+	if err := validateTag(key, value); err != nil {
+		return tagOp{}, err
+	}
+	return tagOp{key: key, value: value}, nil
+}
+
+// apply makes the operation on a tag set and reports whether it changed
+func (op tagOp) apply(tags map[string]string) (bool, error) {
+	current, ok := tags[op.key]
+	if op.remove {
+		delete(tags, op.key)
+		return ok, nil
+	}
+	if ok && current == op.value {
+		return false, nil
+	}
+	if !ok && len(tags) >= maxObjectTags {
+		return false, fmt.Errorf("object already has %d tags, the most S3 allows", maxObjectTags)
+	}
+	tags[op.key] = op.value
+	return true, nil
+}
+
+// describe returns the operation as shown to the user
+func (op tagOp) describe() string {
+	if op.remove {
+		return fmt.Sprintf("remove tag '%s'", displayKey(op.key))
+	}
+	return fmt.Sprintf("add tag '%s=%s'", displayKey(op.key), displayKey(op.value))
+}
+
+// putTags writes a tag set, deleting the tagging when it is empty
+func putTags(ctx context.Context, client *S3Client, bucket, key string, tags map[string]string) error {
+	if len(tags) == 0 {
+		return client.DeleteObjectTagging(ctx, bucket, key)
+	}
+	return client.PutObjectTagging(ctx, bucket, key, tags)
+}
+
+// saveTags replaces the tags of the object in the tag editor
+func (m Model) saveTags() tea.Cmd {
+	key := m.tagKey
+	tags := make(map[string]string, len(m.tagFields))
+	for _, field := range m.tagFields {
+		tags[field.name] = field.value
+	}
+	return tea.Cmd(func() tea.Msg {
+		err := putTags(context.Background(), m.s3Client, m.bucket, key, tags)
+		return tagsSavedMsg{key: key, count: len(tags), err: err}
+	})
+}
+
+// retagObjects applies a tag operation to every object of the targets,
+// expanding folders first
+func (m Model) retagObjects(bulk *tagBulk) tea.Cmd {
+	client, bucket := m.s3Client, m.bucket
+	return tea.Cmd(func() tea.Msg {
+		keys, err := expandKeys(context.Background(), client, bucket, bulk.targets)
+		if err != nil {
+			return transferStartedMsg{err: err}
+		}
+		op := bulk.op
+		return startBatch(batchJob{
+			title: "Tagging: " + op.describe(),
+			keys:  keys,
+			apply: func(ctx context.Context, key string) error {
+				tags, err := client.GetObjectTagging(ctx, bucket, key)
+				if err != nil {
+					return err
+				}
+				changed, err := op.apply(tags)
+				if err != nil || !changed {
+					return err
+				}
+				return putTags(ctx, client, bucket, key, tags)
+			},
+		})
+	})
+}
+
+// applyTagsLoaded opens the tag editor
+func (m Model) applyTagsLoaded(msg tagsLoadedMsg) (tea.Model, tea.Cmd) {
+	m.loading = false
+	if msg.err != nil {
+		m.err = msg.err
+		return m, nil
+	}
+	if m.viewMode != ViewTags {
+		m.tagReturn = m.viewMode
+	}
+	m.tagKey = msg.key
+	m.tagOriginal = tagFields(msg.tags)
+	m.tagFields = append([]infoField{}, m.tagOriginal...)
+	m.tagCursor = 0
+	m.viewMode = ViewTags
+	m.err = nil
+	m.statusMessage = ""
+	return m, nil
+}
+
+// applyTagsSaved reports the result of saving the tag editor
+func (m Model) applyTagsSaved(msg tagsSavedMsg) (tea.Model, tea.Cmd) {
+	m.loading = false
+	if msg.err != nil {
+		// Stay in the editor so the changes are not lost
+		m.err = msg.err
+		return m, nil
+	}
+	m.viewMode = m.tagReturn
+	m.tagFields = nil
+	m.tagOriginal = nil
+	m.err = nil
+	m.statusMessage = fmt.Sprintf("✓ Saved %d tag(s) on '%s'", msg.count, displayKey(keyName(msg.key)))
+	if m.viewMode == ViewInfo {
+		// Show the new tags in the info panel
+		m.loading = true
+		return m, m.inspectObject(m.infoKey)
+	}
+	return m, nil
+}
+
+// setTag sets the value of a tag in the editor, adding it if it is new
+func (m *Model) setTag(key, value string) error {
+	if err := validateTag(key, value); err != nil {
+		return err
+	}
+	for i, field := range m.tagFields {
+		if field.name == key {
+			m.tagFields[i].value = value
+			m.tagCursor = i
+			return nil
+		}
+	}
+	if len(m.tagFields) >= maxObjectTags {
+		return fmt.Errorf("objects can have at most %d tags", maxObjectTags)
+	}
+	m.tagFields = append(m.tagFields, infoField{key, value})
+	m.tagCursor = len(m.tagFields) - 1
+	return nil
+}
+
+// tagsChanged reports whether the editor differs from the tags as read
+func (m Model) tagsChanged() bool {
+	if len(m.tagFields) != len(m.tagOriginal) {
+		return true
+	}
+	original := make(map[string]string, len(m.tagOriginal))
+	for _, field := range m.tagOriginal {
+		original[field.name] = field.value
+	}
+	for _, field := range m.tagFields {
+		if value, ok := original[field.name]; !ok || value != field.value {
+			return true
+		}
+	}
+	return false
+}
+
+// updateTags handles tag editor updates
+func (m Model) updateTags(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c":
+		return m, tea.Quit
+	case "esc", "q":
+		m.viewMode = m.tagReturn
+		m.tagFields = nil
+		m.tagOriginal = nil
+		m.err = nil
+		m.statusMessage = "Tags left unchanged"
+	case "up", "k":
+		if m.tagCursor > 0 {
+			m.tagCursor--
+		}
+	case "down", "j":
+		if m.tagCursor < len(m.tagFields)-1 {
+			m.tagCursor++
+		}
+	case "enter", "e":
+		if len(m.tagFields) > 0 {
+			field := m.tagFields[m.tagCursor]
+			m.openPrompt("tag_value", "Edit Tag", displayKey(field.name)+":", field.value)
+		}
+	case "a":
+		m.openPrompt("tag_add", "Add Tag", "Tag (key=value):", "")
+	case "x", "d":
+		if len(m.tagFields) > 0 {
+			m.tagFields = append(m.tagFields[:m.tagCursor:m.tagCursor], m.tagFields[m.tagCursor+1:]...)
+			m.tagCursor = max(min(m.tagCursor, len(m.tagFields)-1), 0)
+		}
+	case "u":
+		m.tagFields = append([]infoField{}, m.tagOriginal...)
+		m.tagCursor = max(min(m.tagCursor, len(m.tagFields)-1), 0)
+		m.statusMessage = "Changes undone"
+	case "s", "ctrl+s":
+		if !m.tagsChanged() {
+			m.statusMessage = "Nothing changed"
+			return m, nil
+		}
+		m.loading = true
+		m.err = nil
+		return m, m.saveTags()
+	}
+	return m, nil
+}
+
+// viewTags renders the tag editor
+func (m Model) viewTags() string {
+	var s strings.Builder
+
+	s.WriteString(titleStyle.Render(fmt.Sprintf("Tags: %s", displayKey(keyName(m.tagKey)))))
+	s.WriteString("\n\n")
+
+	original := make(map[string]string, len(m.tagOriginal))
+	for _, field := range m.tagOriginal {
+		original[field.name] = field.value
+	}
+	nameWidth := 0
+	for _, field := range m.tagFields {
+		nameWidth = max(nameWidth, len(displayKey(field.name)))
+	}
+	valueWidth := max(m.width-nameWidth-22, 20)
+
+	var content strings.Builder
+	if len(m.tagFields) == 0 {
+		content.WriteString(helpStyle.Render("No tags; press a to add one") + "\n")
+	}
+	for i, field := range m.tagFields {
+		cursor := " "
+		if i == m.tagCursor {
+			cursor = ">"
+		}
+		mark := " "
+		if value, ok := original[field.name]; !ok || value != field.value {
+			mark = "*"
+		}
+		line := fmt.Sprintf("%s%s %-*s  %s", cursor, mark, nameWidth, displayKey(field.name), truncateString(displayKey(field.value), valueWidth))
+		if i == m.tagCursor {
+			line = selectedStyle.Render(line)
+		}
+		content.WriteString(line + "\n")
+	}
+	content.WriteString(helpStyle.Render(fmt.Sprintf("\n%d of %d tags", len(m.tagFields), maxObjectTags)))
+
+	if m.loading {
+		content.WriteString("\n\nSaving...")
+	} else if m.err != nil {
+		content.WriteString("\n\n" + errorStyle.Render(fmt.Sprintf("Error: %s", m.err.Error())))
+	} else if m.statusMessage != "" {
+		content.WriteString("\n\n" + successStyle.Render(m.statusMessage))
+	}
+	s.WriteString(previewStyle.Render(content.String()))
+
+	s.WriteString("\n\n")
+	s.WriteString(helpStyle.Render("↑/k,↓/j: move • enter/e: edit value • a: add • x: remove • u: undo all • s: save • esc: cancel"))
+
+	if m.width > 0 && m.height > 0 {
+		centered := centerStyle.Width(m.width).Render(lipgloss.NewStyle().Align(lipgloss.Left).Render(s.String()))
+		return verticalCenterStyle.Height(m.height).Render(centered)
+	}
+	return s.String()
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseTagOp(t *testing.T) {
+	longKey := strings.Repeat("k", maxTagKeyLength)
+	longValue := strings.Repeat("v", maxTagValueLength)
+	tests := []struct {
+		input   string
+		want    tagOp
+		wantErr bool
+	}{
+		{"env=prod", tagOp{key: "env", value: "prod"}, false},
+		{"+env=prod", tagOp{key: "env", value: "prod"}, false},
+		{" env = prod ", tagOp{key: "env", value: "prod"}, false},
+		{"url=a=b", tagOp{key: "url", value: "a=b"}, false},
+		{"empty=", tagOp{key: "empty"}, false},
+		{"ключ=значение", tagOp{key: "ключ", value: "значение"}, false},
+		{longKey + "=" + longValue, tagOp{key: longKey, value: longValue}, false},
+		{"-env", tagOp{remove: true, key: "env"}, false},
+		{"- env ", tagOp{remove: true, key: "env"}, false},
+		{"-", tagOp{}, true},
+		{"- ", tagOp{}, true},
+		{"env", tagOp{}, true},
+		{"", tagOp{}, true},
+		{"=prod", tagOp{}, true},
+		{" =prod", tagOp{}, true},
+		{longKey + "k=v", tagOp{}, true},
+		{"k=" + longValue + "v", tagOp{}, true},
+	}
+	for _, tt := range tests {
+		got, err := parseTagOp(tt.input)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("parseTagOp(%q) = %+v, want an error", tt.input, got)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("parseTagOp(%q) = %+v, %v, want %+v", tt.input, got, err, tt.want)
+		}
+	}
+}
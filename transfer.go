@@ -0,0 +1,290 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// transferItem is a single object copied or moved by a transfer job
+type transferItem struct {
+	srcKey string
+	dstKey string
+	size   int64
+	etag   string
+	root   string // Destination folder listed during verification; "" for single files, checked with HEAD
+	move   bool   // Delete the source once the copy is confirmed
+	cut    string // Cut key the item was expanded from, for moves of cut items
+}
+
+// transferJob describes a batch copy or move between two locations
+type transferJob struct {
+	title     string
+	srcClient *S3Client
+	srcBucket string
+	dstClient *S3Client
+	dstBucket string
+	items     []transferItem
+	moved     []string // Cut keys settled without a transfer, such as moves onto themselves
+}
+
+// Messages for transfer jobs
+type transferStartedMsg struct {
+	title   string
+	total   int
+	updates <-chan tea.Msg
+	err     error
+}
+
+type transferProgressMsg struct {
+	done      int
+	total     int
+	current   string
+	verifying bool
+}
+
+type transferDoneMsg struct {
+	title   string
+	moves   int
+	copied  int
+	failed  int
+	missing int
+	deleted int
+	moved   []string // Cut keys whose every object was moved
+	err     error
+}
+
+// uniqueName returns name, or name with a _copy_N suffix if it is already taken
+func uniqueName(name string, isDir bool, taken map[string]bool) string {
+	if !taken[name] {
+		return name
+	}
+
+	ext := ""
+	if !isDir {
+		ext = filepath.Ext(name)
+	}
+	nameWithoutExt := strings.TrimSuffix(name, ext)
+
+	for counter := 1; ; counter++ {
+		candidate := fmt.Sprintf("%s_copy_%d%s", nameWithoutExt, counter, ext)
+		if !taken[candidate] {
+			return candidate
+		}
+	}
+}
+
+// expandTransferItem turns a yanked key into transfer items. Folder keys (and
+// dstKey for them) end in "/" and are expanded recursively so that every
+// object below them is copied.
+func expandTransferItem(ctx context.Context, client *S3Client, bucket, key, dstKey string) ([]transferItem, error) {
+	if !strings.HasSuffix(key, "/") {
+		info, err := client.HeadObject(ctx, bucket, key)
+		if objectNotFound(err) {
+			return nil, fmt.Errorf("'%s' no longer exists", displayKey(keyName(key)))
+		}
+		if err != nil {
+			return nil, err
+		}
+		return []transferItem{{srcKey: key, dstKey: dstKey, size: info.Size, etag: info.ETag}}, nil
+	}
+
+	objects, err := client.ListAllObjects(ctx, bucket, key)
+	if err != nil {
+		return nil, err
+	}
+	if len(objects) == 0 {
+		return nil, fmt.Errorf("folder '%s' is empty", displayKey(keyName(key)))
+	}
+
+	dstPrefix := dstKey
+	items := make([]transferItem, 0, len(objects))
+	for _, obj := range objects {
+		items = append(items, transferItem{
+			srcKey: obj.Key,
+			dstKey: dstPrefix + strings.TrimPrefix(obj.Key, key),
+			size:   obj.Size,
+			etag:   obj.ETag,
+			root:   dstPrefix,
+		})
+	}
+	return items, nil
+}
+
+// planPrefixMove plans moving every object under oldPrefix to newPrefix in one bucket
+func planPrefixMove(ctx context.Context, client *S3Client, bucket, oldPrefix, newPrefix string) (transferJob, error) {
+	items, err := expandTransferItem(ctx, client, bucket, oldPrefix, newPrefix)
+	if err != nil {
+		return transferJob{}, err
+	}
+	for i := range items {
+		items[i].move = true
+	}
+
+	return transferJob{
+		title:     fmt.Sprintf("Renaming '%s' to '%s'", displayKey(keyName(oldPrefix)), displayKey(keyName(newPrefix))),
+		srcClient: client,
+		srcBucket: bucket,
+		dstClient: client,
+		dstBucket: bucket,
+		items:     items,
+	}, nil
+}
+
+// startTransfer runs a job in the background and returns a message carrying its update channel
+func startTransfer(job transferJob) tea.Msg {
+	updates := make(chan tea.Msg, 16)
+	go runTransfer(job, updates)
+	return transferStartedMsg{title: job.title, total: len(job.items), updates: updates}
+}
+
+// waitForUpdates waits for the next update of a background job
+func waitForUpdates(updates <-chan tea.Msg) tea.Cmd {
+	return tea.Cmd(func() tea.Msg {
+		msg, ok := <-updates
+		if !ok {
+			return nil
+		}
+		return msg
+	})
+}
+
+// runTransfer copies all items, verifies that every destination key exists
+// with the expected size, and for moves deletes only the verified sources
+func runTransfer(job transferJob, updates chan<- tea.Msg) {
+	defer close(updates)
+	ctx := context.Background()
+	total := len(job.items)
+
+	result := transferDoneMsg{title: job.title, moved: job.moved}
+	copied := make([]bool, total)
+	for i, item := range job.items {
+		updates <- transferProgressMsg{done: i, total: total, current: item.srcKey}
+
+		err := TransferObject(ctx, job.srcClient, job.srcBucket, item.srcKey, job.dstClient, job.dstBucket, item.dstKey)
+		if err != nil {
+			result.failed++
+			result.err = fmt.Errorf("%s: %w", item.srcKey, err)
+			continue
+		}
+		copied[i] = true
+		result.copied++
+	}
+
+	// Verification pass: list each destination folder once, HEAD single files,
+	// and check every key arrived intact
+	updates <- transferProgressMsg{done: total, total: total, verifying: true}
+	listings := make(map[string]map[string]int64)
+	verified := make([]bool, total)
+	for i, item := range job.items {
+		if !copied[i] {
+			continue
+		}
+		if item.root == "" {
+			info, err := job.dstClient.HeadObject(ctx, job.dstBucket, item.dstKey)
+			if err != nil && !objectNotFound(err) {
+				result.err = fmt.Errorf("verification failed: %w", err)
+			}
+			if err == nil && info.Size == item.size {
+				verified[i] = true
+			} else {
+				result.missing++
+			}
+			continue
+		}
+		listing, ok := listings[item.root]
+		if !ok {
+			listing = make(map[string]int64)
+			objects, err := job.dstClient.ListAllObjects(ctx, job.dstBucket, item.root)
+			if err != nil {
+				result.err = fmt.Errorf("verification failed: %w", err)
+			}
+			for _, obj := range objects {
+				listing[obj.Key] = obj.Size
+			}
+			listings[item.root] = listing
+		}
+
+		if size, exists := listing[item.dstKey]; exists && size == item.size {
+			verified[i] = true
+		} else {
+			result.missing++
+		}
+	}
+
+	// Moves delete the source only after a HEAD of the copy confirms it matches
+	deleted := make([]bool, total)
+	for i, item := range job.items {
+		if !item.move {
+			continue
+		}
+		result.moves++
+		if !verified[i] {
+			continue
+		}
+
+		updates <- transferProgressMsg{done: i, total: total, current: "deleting " + item.srcKey}
+		info, err := job.dstClient.HeadObject(ctx, job.dstBucket, item.dstKey)
+		if err != nil {
+			result.err = err
+			continue
+		}
+		source, err := job.srcClient.HeadObject(ctx, job.srcBucket, item.srcKey)
+		if err != nil {
+			result.err = err
+			continue
+		}
+		if !sameContent(item, source, info) {
+			result.err = fmt.Errorf("%s: copy does not match source (ETag %s, expected %s); source kept", item.srcKey, info.ETag, item.etag)
+			continue
+		}
+		if err := job.srcClient.DeleteObject(ctx, job.srcBucket, item.srcKey); err != nil {
+			result.err = err
+			continue
+		}
+		deleted[i] = true
+		result.deleted++
+	}
+
+	// A cut item counts as moved once every object below it was deleted
+	kept := make(map[string]bool)
+	for i, item := range job.items {
+		if item.move && !deleted[i] {
+			kept[item.cut] = true
+		}
+	}
+	for _, item := range job.items {
+		if item.move && item.cut != "" && !kept[item.cut] {
+			kept[item.cut] = true
+			result.moved = append(result.moved, item.cut)
+		}
+	}
+
+	updates <- result
+}
+
+// sameContent reports whether a copied object matches its source. ETags are
+// compared when both are plain MD5 digests; multipart ETags (containing "-")
+// depend on the part layout, and ETags of SSE-KMS and SSE-C objects are not
+// digests of the data, so only the size can be compared for those.
+func sameContent(item transferItem, source, copied *ObjectInfo) bool {
+	if copied.Size != item.size {
+		return false
+	}
+	if item.etag == "" || strings.Contains(item.etag, "-") || strings.Contains(copied.ETag, "-") {
+		return true
+	}
+	if opaqueETag(source) || opaqueETag(copied) {
+		return true
+	}
+	return copied.ETag == item.etag
+}
+
+// opaqueETag reports whether an object's ETag is not the MD5 of its data,
+// which is the case for objects encrypted with KMS or a customer key
+func opaqueETag(info *ObjectInfo) bool {
+	return strings.HasPrefix(info.ServerSideEncryption, "aws:kms") || info.SSECustomerAlgorithm != ""
+}
@@ -5,11 +5,13 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"regexp"
 	"sort"
 	"strings"
 	"time"
 	"unicode/utf8"
 
+	"github.com/alecthomas/chroma/v2"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 )
@@ -24,6 +26,14 @@ const (
 	ViewUpload
 	ViewRename
 	ViewConfirm
+	ViewPrompt
+	ViewProgress
+	ViewInfo
+	ViewMetadata
+	ViewReport
+	ViewTags
+	ViewVersions
+	ViewShare
 )
 
 // LocalItem represents a local file or directory
@@ -41,50 +51,157 @@ type DirStats struct {
 	DateTimeout  bool
 }
 
+// paneState holds the browser state of the pane that is not focused
+type paneState struct {
+	id            int
+	s3Client      *S3Client
+	profile       string
+	bucket        string
+	currentPath   string
+	objects       []S3Object
+	cursor        int
+	scrollOffset  int
+	selectedFiles []string
+	dirStatsCache map[string]DirStats
+	loading       bool
+}
+
 // Model represents the application state
 type Model struct {
-	s3Client        *S3Client
-	bucket          string
-	currentPath     string
-	objects         []S3Object
-	cursor          int
-	viewMode        ViewMode
-	previewContent  string
-	previewFileName string
-	previewLines    []string
-	previewScroll   int
-	previewWidth    int
-	localItems      []LocalItem
-	localPath       string
-	err             error
-	statusMessage   string
-	loading         bool
-	width           int
-	height          int
-	yankedFiles     []string // Keys of files that have been yanked for copying
-	selectedFiles   []string // Keys of files/folders that have been selected for operations
-	renameInput     string   // Current input for renaming
-	renameOriginal  string   // Original filename being renamed
-	renameCursor    int      // Cursor position in rename input
-	scrollOffset    int      // Current scroll offset for file list
-	confirmAction   string   // Action being confirmed (delete, download, upload)
-	confirmTarget   string   // Target file/path for confirmation
-	confirmData     interface{} // Additional data for confirmation action
-	dirStatsCache   map[string]DirStats // Cache for directory statistics
+	s3Client          *S3Client
+	profile           string
+	bucket            string
+	currentPath       string // Prefix of the current folder, with trailing "/" ("" for the root)
+	objects           []S3Object
+	cursor            int
+	viewMode          ViewMode
+	previewFileName   string
+	previewLines      []string
+	previewScroll     int
+	previewWidth      int
+	previewData       []byte             // Bytes of the object currently held in memory
+	previewSize       int64              // Total size of the previewed object
+	previewStart      int64              // Object offset of the first byte in previewData
+	previewOffsets    []int64            // Object offset at which each preview line starts
+	previewLineBase   int                // Line number (0-based) of previewLines[0], -1 if unknown
+	previewFetching   bool               // Whether a ranged read is in flight
+	previewBinary     bool               // Whether the previewed object is not valid UTF-8
+	previewHex        bool               // Whether the preview shows a hex dump
+	previewPretty     bool               // Whether JSON or XML is shown re-indented
+	previewType       string             // Content-Type of the previewed object
+	previewLexer      chroma.Lexer       // Highlighter chosen for the object, nil for plain text
+	previewDelimiter  rune               // Field separator of CSV and TSV objects, or 0
+	previewCodec      string             // Compression of the object, "" if stored plain
+	previewStored     int64              // Stored (compressed) size of the object
+	previewSizeKnown  bool               // Whether previewSize is final; false while decompressing
+	previewStream     *decompressStream  // Decompressed reader of a compressed object
+	previewETag       string             // ETag of the previewed object, to notice changes while following
+	previewWrap       bool               // Whether long lines wrap instead of scrolling sideways
+	previewCol        int                // First column shown of long lines (horizontal scroll)
+	previewFilter     string             // Previewer whose output is shown, "" for the object itself
+	previewFollow     bool               // Whether the object is polled for new data, like tail -f
+	previewVersion    string             // Version being previewed, "" for the current object
+	followID          int                // Identifier of the current follow session
+	hexData           []byte             // Bytes loaded for the hex dump
+	hexStart          int64              // Object offset of the first byte in hexData
+	hexOffset         int64              // Object offset of the top hex dump row
+	hexSummary        string             // Format detected from the object's magic bytes
+	hexFetching       bool               // Whether a hex window read is in flight
+	previewTable      bool               // Whether CSV, TSV or JSON Lines are shown as a table
+	tableKind         string             // Table format of the previewed object, "" if not tabular
+	tableColumns      []string           // Column names (CSV header or flattened JSON fields)
+	tableRows         [][]string         // Parsed fields of each preview line, nil for the header
+	tableWidths       []int              // Display width of each column
+	tableCol          int                // First column shown (horizontal scroll)
+	tableHidden       map[string]bool    // Columns hidden with the column picker
+	tablePicker       bool               // Whether the column picker is open
+	tablePickerCursor int                // Cursor in the column picker
+	searchPattern     *regexp.Regexp     // Compiled preview search pattern, nil before the first search
+	searchText        string             // Search pattern as typed
+	searchBackward    bool               // Whether the search was started with "?"
+	searchMatch       int64              // Object offset of the line of the current match
+	searchID          int                // Identifier of the latest background scan
+	searchCancel      context.CancelFunc // Stops the running background scan, nil if none
+	searchUpdates     <-chan tea.Msg     // Progress channel of the running background scan
+	archive           *archiveIndex      // Archive being browsed as a folder, nil outside archives
+	archivePath       string             // Folder inside the archive, with trailing "/" ("" for its root)
+	localItems        []LocalItem
+	localPath         string
+	err               error
+	statusMessage     string
+	loading           bool
+	width             int
+	height            int
+	yankedFiles       []string            // Keys of files (and folders, with trailing "/") that have been yanked for copying
+	selectedFiles     []string            // Keys of files/folders that have been selected for operations
+	renameInput       string              // Current input for renaming
+	renameOriginal    string              // Original filename being renamed
+	renameCursor      int                 // Cursor position in rename input
+	scrollOffset      int                 // Current scroll offset for file list
+	confirmAction     string              // Action being confirmed (delete, download, upload)
+	confirmTarget     string              // Target file/path for confirmation
+	confirmData       interface{}         // Additional data for confirmation action
+	dirStatsCache     map[string]DirStats // Cache for directory statistics
+	showDeleted       bool                // Whether objects hidden by delete markers are shown greyed out
+	paneID            int                 // Identifier of the focused pane (0 or 1)
+	dualPane          bool                // Whether a second pane is open
+	otherPane         paneState           // State of the unfocused pane when dualPane is set
+	cutFiles          []string            // Keys of files and folders that have been cut for moving
+	yankClient        *S3Client           // Client of the pane the yanked or cut items came from
+	yankBucket        string              // Bucket the yanked or cut items came from
+	promptAction      string              // Action the text prompt was opened for
+	promptTitle       string              // Title of the text prompt
+	promptLabel       string              // Label shown above the prompt input
+	promptInput       string              // Current input for the text prompt
+	promptCursor      int                 // Cursor position in prompt input
+	promptReturn      ViewMode            // View to return to when the prompt closes
+	progressTitle     string              // Title of the running transfer
+	progressDone      int                 // Number of objects processed by the running transfer
+	progressTotal     int                 // Total number of objects in the running transfer
+	progressCurrent   string              // Key currently being processed
+	progressVerify    bool                // Whether the running transfer is in its verification pass
+	progressUpdates   <-chan tea.Msg      // Update channel of the running transfer
+	settings          *Settings           // Options from .s4cfg
+	openKey           string              // Object the "Open With" prompt is for
+	infoKey           string              // Object shown in the info panel
+	infoFields        []infoField         // Metadata lines of the info panel
+	infoCursor        int                 // Selected line of the info panel
+	infoReturn        ViewMode            // View to return to when the info panel closes
+	metaKeys          []string            // Objects the metadata editor applies to
+	metaOriginal      []infoField         // Headers and user metadata of the first object as read
+	metaFields        []infoField         // Headers and user metadata as edited
+	metaCursor        int                 // Selected line of the metadata editor
+	tagKey            string              // Object shown in the tag editor
+	tagOriginal       []infoField         // Tags of the object as read
+	tagFields         []infoField         // Tags as edited
+	tagCursor         int                 // Selected tag in the tag editor
+	tagReturn         ViewMode            // View to return to when the tag editor closes
+	tagTargets        []string            // Items the bulk tag prompt is for
+	versionsPrefix    string              // Key, or folder prefix, whose versions are listed
+	versions          []ObjectVersion     // Versions and delete markers, by key and newest first
+	versionsTruncated bool                // Whether there were more versions than listed
+	versionsCursor    int                 // Selected line of the versions view
+	versionTarget     ObjectVersion       // Version the permanent delete prompt is for
+	shareKey          string              // Object the share prompt and dialog are for
+	shareMethod       string              // HTTP method of the presigned URL
+	shareURL          string              // Presigned URL shown in the share dialog
+	shareExpires      time.Time           // When the presigned URL stops working
+	shareQR           bool                // Whether the share dialog shows a QR code
+	manifestTargets   []string            // Selection the presigned URL manifest is for
+	storageTargets    []string            // Selection the storage class and restore prompts are for
+	reportTitle       string              // Title of the finished batch job
+	reportSummary     string              // Counts of the finished batch job
+	reportLines       []string            // Failures of the finished batch job
+	reportScroll      int                 // First failure shown in the report
 }
 
 // Messages for async operations
 type objectsLoadedMsg struct {
+	pane    int
 	objects []S3Object
 	err     error
 }
 
-type previewLoadedMsg struct {
-	content string
-	file    string
-	err     error
-}
-
 type fileDownloadedMsg struct {
 	filename string
 	err      error
@@ -118,13 +235,9 @@ type batchDownloadedMsg struct {
 	err             error
 }
 
-type fileCopiedMsg struct {
-	sourceKey string
-	destKey   string
-	err       error
-}
-
 type fileRenamedMsg struct {
+	client *S3Client
+	bucket string
 	oldKey string
 	newKey string
 	err    error
@@ -145,7 +258,13 @@ type errorMsg struct {
 	err error
 }
 
+type paneOpenedMsg struct {
+	pane paneState
+	err  error
+}
+
 type dirStatsMsg struct {
+	pane         int
 	dirKey       string
 	size         int64
 	lastModified string
@@ -203,9 +322,14 @@ var (
 )
 
 // NewModel creates a new TUI model
-func NewModel(s3Client *S3Client, bucket string) Model {
+func NewModel(s3Client *S3Client, bucket string, settings *Settings) Model {
+	if settings == nil {
+		settings = &Settings{}
+	}
 	return Model{
 		s3Client:      s3Client,
+		settings:      settings,
+		profile:       "default",
 		bucket:        bucket,
 		currentPath:   "",
 		objects:       []S3Object{},
@@ -243,9 +367,38 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m.updateRename(msg)
 		case ViewConfirm:
 			return m.updateConfirm(msg)
+		case ViewPrompt:
+			return m.updatePrompt(msg)
+		case ViewInfo:
+			return m.updateInfo(msg)
+		case ViewMetadata:
+			return m.updateMetadata(msg)
+		case ViewReport:
+			return m.updateReport(msg)
+		case ViewTags:
+			return m.updateTags(msg)
+		case ViewVersions:
+			return m.updateVersions(msg)
+		case ViewShare:
+			return m.updateShare(msg)
+		case ViewProgress:
+			if msg.String() == "ctrl+c" {
+				return m, tea.Quit
+			}
+			return m, nil
 		}
 
 	case objectsLoadedMsg:
+		if m.dualPane && msg.pane != m.paneID {
+			// Listing finished for the unfocused pane
+			m.otherPane.loading = false
+			if msg.err == nil {
+				m.otherPane.objects = msg.objects
+				m.otherPane.cursor = 0
+				m.otherPane.scrollOffset = 0
+			}
+			return m, nil
+		}
 		m.loading = false
 		if msg.err != nil {
 			m.err = msg.err
@@ -254,11 +407,11 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.cursor = 0
 			m.scrollOffset = 0
 			m.err = nil
-			
+
 			// Trigger directory stats calculations for directories that don't have cached stats
 			var cmds []tea.Cmd
 			for _, obj := range m.objects {
-				if obj.IsDir {
+				if obj.IsDir && !obj.Deleted {
 					if _, exists := m.dirStatsCache[obj.Key]; !exists {
 						cmds = append(cmds, m.calculateDirStats(obj.Key))
 					}
@@ -270,17 +423,113 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 		return m, nil
 
-	case previewLoadedMsg:
+	case archiveOpenedMsg:
+		m.loading = false
+		if msg.err != nil {
+			m.err = msg.err
+			return m, nil
+		}
+		m.archive = msg.index
+		m.showArchiveFolder("")
+		m.err = nil
+		m.statusMessage = fmt.Sprintf("✓ Opened archive '%s' (%d entries)", displayKey(keyName(msg.index.key)), len(msg.index.entries))
+		return m, nil
+
+	case previewChunkMsg:
+		return m.applyPreviewChunk(msg)
+
+	case hexChunkMsg:
+		return m.applyHexChunk(msg)
+
+	case searchStartedMsg:
+		if msg.id != m.searchID || m.searchCancel == nil {
+			return m, nil
+		}
+		m.searchUpdates = msg.updates
+		return m, waitForUpdates(msg.updates)
+
+	case searchProgressMsg:
+		if msg.id != m.searchID || m.searchUpdates == nil {
+			return m, nil
+		}
+		m.statusMessage = fmt.Sprintf("Searching... %s scanned (esc to cancel)", formatSize(msg.scanned))
+		return m, waitForUpdates(m.searchUpdates)
+
+	case searchDoneMsg:
+		return m.applySearchDone(msg)
+
+	case followTickMsg:
+		if !m.previewFollow || msg.id != m.followID || msg.key != m.previewFileName {
+			return m, nil
+		}
+		if m.previewFetching {
+			// Let the ranged read in flight land first
+			return m, m.followTick()
+		}
+		return m, m.pollFollow()
+
+	case followPolledMsg:
+		return m.applyFollowPoll(msg)
+
+	case editDownloadedMsg:
+		m.loading = false
+		if msg.err != nil {
+			m.err = msg.err
+			return m, nil
+		}
+		return m, runEditor(msg.session)
+
+	case editorClosedMsg:
+		return m.applyEditorClosed(msg)
+
+	case fileEditedMsg:
+		return m.applyFileEdited(msg)
+
+	case openWithMsg:
+		return m.applyOpenWith(msg)
+
+	case openFileReadyMsg:
+		return m.applyOpenFileReady(msg)
+
+	case objectInfoMsg:
+		return m.applyObjectInfo(msg)
+
+	case metadataLoadedMsg:
+		return m.applyMetadataLoaded(msg)
+
+	case batchDoneMsg:
+		return m.applyBatchDone(msg)
+
+	case tagsLoadedMsg:
+		return m.applyTagsLoaded(msg)
+
+	case tagsSavedMsg:
+		return m.applyTagsSaved(msg)
+
+	case versionsLoadedMsg:
+		return m.applyVersionsLoaded(msg)
+
+	case versionActionMsg:
+		return m.applyVersionAction(msg)
+
+	case presignedMsg:
+		return m.applyPresigned(msg)
+
+	case manifestWrittenMsg:
+		return m.applyManifestWritten(msg)
+
+	case clipboardMsg:
 		if msg.err != nil {
 			m.err = msg.err
 		} else {
-			m.previewContent = msg.content
-			m.previewFileName = msg.file
-			m.previewLines = strings.Split(msg.content, "\n")
-			m.previewScroll = 0
-			m.previewWidth = m.calculatePreviewWidth()
-			m.viewMode = ViewPreview
 			m.err = nil
+			m.statusMessage = fmt.Sprintf("✓ Copied %s to clipboard", msg.what)
+		}
+		return m, nil
+
+	case viewerClosedMsg:
+		if msg.err != nil {
+			m.err = fmt.Errorf("viewer failed: %w", msg.err)
 		}
 		return m, nil
 
@@ -394,42 +643,72 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 		return m, nil
 
-	case fileCopiedMsg:
+	case fileRenamedMsg:
 		m.loading = false
 		if msg.err != nil {
 			m.err = msg.err
 			m.statusMessage = ""
 		} else {
 			m.err = nil
-			// Handle both single file and multiple file copy messages
-			if strings.Contains(msg.sourceKey, "files") {
-				// Multiple files copied
-				m.statusMessage = fmt.Sprintf("✓ Copied %s successfully", msg.sourceKey)
-			} else {
-				// Single file copied (legacy support)
-				sourceFilename := filepath.Base(msg.sourceKey)
-				destFilename := filepath.Base(msg.destKey)
-				m.statusMessage = fmt.Sprintf("✓ Copied '%s' to '%s' successfully", sourceFilename, destFilename)
+			oldFilename := displayKey(keyName(msg.oldKey))
+			newFilename := displayKey(keyName(msg.newKey))
+			m.statusMessage = fmt.Sprintf("✓ Renamed '%s' to '%s' successfully", oldFilename, newFilename)
+			// Yanked or cut references to the old key follow the rename
+			if m.yankClient == msg.client && m.yankBucket == msg.bucket {
+				m.yankedFiles = replaceKey(m.yankedFiles, msg.oldKey, msg.newKey)
+				m.cutFiles = replaceKey(m.cutFiles, msg.oldKey, msg.newKey)
 			}
-			// Refresh the directory to show the new file(s)
+			// Refresh the directory to show the renamed file
 			return m, m.loadObjects()
 		}
 		return m, nil
 
-	case fileRenamedMsg:
-		m.loading = false
+	case transferStartedMsg:
 		if msg.err != nil {
+			m.loading = false
 			m.err = msg.err
 			m.statusMessage = ""
+			return m, nil
+		}
+		m.viewMode = ViewProgress
+		m.progressTitle = msg.title
+		m.progressDone = 0
+		m.progressTotal = msg.total
+		m.progressCurrent = ""
+		m.progressVerify = false
+		m.progressUpdates = msg.updates
+		return m, waitForUpdates(msg.updates)
+
+	case transferProgressMsg:
+		m.progressDone = msg.done
+		m.progressTotal = msg.total
+		m.progressCurrent = msg.current
+		m.progressVerify = msg.verifying
+		return m, waitForUpdates(m.progressUpdates)
+
+	case transferDoneMsg:
+		m.loading = false
+		m.viewMode = ViewBrowser
+		m.progressUpdates = nil
+		for _, key := range msg.moved {
+			m.cutFiles = removeKey(m.cutFiles, key)
+		}
+		if msg.failed > 0 || msg.missing > 0 || msg.deleted < msg.moves {
+			m.err = fmt.Errorf("transferred %d of %d object(s): %d failed, %d missing after verification, %d of %d source(s) removed; last error: %v",
+				msg.copied-msg.missing, msg.copied+msg.failed, msg.failed, msg.missing, msg.deleted, msg.moves, msg.err)
+			m.statusMessage = ""
 		} else {
 			m.err = nil
-			oldFilename := filepath.Base(msg.oldKey)
-			newFilename := filepath.Base(msg.newKey)
-			m.statusMessage = fmt.Sprintf("✓ Renamed '%s' to '%s' successfully", oldFilename, newFilename)
-			// Refresh the directory to show the renamed file
-			return m, m.loadObjects()
+			switch {
+			case msg.moves == 0:
+				m.statusMessage = fmt.Sprintf("✓ Copied %d object(s), all verified", msg.copied)
+			case msg.moves == msg.copied:
+				m.statusMessage = fmt.Sprintf("✓ Moved %d object(s), all verified", msg.copied)
+			default:
+				m.statusMessage = fmt.Sprintf("✓ Copied %d and moved %d object(s), all verified", msg.copied-msg.moves, msg.moves)
+			}
 		}
-		return m, nil
+		return m, m.loadObjects()
 
 	case statusMsg:
 		if msg.isError {
@@ -454,8 +733,27 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			SizeTimeout:  msg.sizeTimeout,
 			DateTimeout:  msg.dateTimeout,
 		}
-		m.dirStatsCache[msg.dirKey] = stats
+		if m.dualPane && msg.pane != m.paneID {
+			m.otherPane.dirStatsCache[msg.dirKey] = stats
+		} else {
+			m.dirStatsCache[msg.dirKey] = stats
+		}
 		return m, nil
+
+	case paneOpenedMsg:
+		if msg.err != nil {
+			m.err = msg.err
+			m.statusMessage = ""
+			return m, nil
+		}
+		// Focus the newly opened pane and load its contents
+		msg.pane.id = 1 - m.paneID
+		m.otherPane = msg.pane
+		m.dualPane = true
+		m.swapPanes()
+		m.err = nil
+		m.statusMessage = fmt.Sprintf("✓ Opened pane on '%s' (%s)", m.bucket, m.profile)
+		return m, m.loadObjects()
 	}
 
 	return m, nil
@@ -463,6 +761,50 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 // updateBrowser handles browser view updates
 func (m Model) updateBrowser(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if m.archive != nil {
+		switch msg.String() {
+		case "enter", "l", "o", "backspace", "h", "esc", "d", "r", "u", "x", "y", "X", "p", " ", "tab", "B":
+			return m.updateArchive(msg)
+		}
+	}
+
+	if len(m.objects) > 0 && m.objects[m.cursor].Deleted && !m.objects[m.cursor].IsDir {
+		// Deleted files have no content, only versions
+		key := m.objects[m.cursor].Key
+		blocked := false
+		switch msg.String() {
+		case "enter", "l", "o":
+			m.loading = true
+			m.err = nil
+			return m, m.loadVersions(key)
+		case "i", "O", "e", "r", "y", "X":
+			blocked = true
+		case "d", "x", "M", "T", "S", "C", "R":
+			blocked = len(m.selectedFiles) == 0
+		}
+		if blocked {
+			m.err = fmt.Errorf("'%s' is deleted; U undeletes it, V lists its versions", displayKey(keyName(key)))
+			return m, nil
+		}
+	}
+
+	if len(m.objects) > 0 && m.archive == nil {
+		// Archived files cannot be read until they are restored
+		if err := archivedReadError(m.objects[m.cursor]); err != nil {
+			blocked := false
+			switch msg.String() {
+			case "enter", "l", "o", "O", "e":
+				blocked = true
+			case "d":
+				blocked = len(m.selectedFiles) == 0
+			}
+			if blocked {
+				m.err = err
+				return m, nil
+			}
+		}
+	}
+
 	switch msg.String() {
 	case "ctrl+c", "q":
 		return m, tea.Quit
@@ -491,6 +833,11 @@ func (m Model) updateBrowser(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 				// Clear selections when navigating to different directory
 				m.selectedFiles = []string{}
 				return m, m.loadObjects()
+			} else if archiveFormat(selected.Key) != "" {
+				// Browse the archive like a folder
+				m.loading = true
+				m.err = nil
+				return m, m.openArchive(selected.Key, selected.Size)
 			} else {
 				// Preview file
 				return m, m.previewFileContent(selected.Key)
@@ -500,12 +847,7 @@ func (m Model) updateBrowser(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	case "backspace", "h":
 		// Go back to parent directory
 		if m.currentPath != "" {
-			parts := strings.Split(m.currentPath, "/")
-			if len(parts) > 1 {
-				m.currentPath = strings.Join(parts[:len(parts)-1], "/")
-			} else {
-				m.currentPath = ""
-			}
+			m.currentPath = parentPrefix(m.currentPath)
 			m.loading = true
 			// Clear directory stats cache when navigating to ensure fresh calculations
 			m.dirStatsCache = make(map[string]DirStats)
@@ -515,17 +857,15 @@ func (m Model) updateBrowser(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		}
 
 	case "r":
-		// Rename selected file
+		// Rename selected file or folder (folders are moved by prefix)
 		if len(m.objects) > 0 {
 			selected := m.objects[m.cursor]
-			if !selected.IsDir {
-				m.renameOriginal = selected.Key
-				m.renameInput = filepath.Base(selected.Key)
-				m.renameCursor = len(m.renameInput) // Set cursor at end
-				m.viewMode = ViewRename
-				m.err = nil
-				m.statusMessage = ""
-			}
+			m.renameOriginal = selected.Key
+			m.renameInput = keyName(selected.Key)
+			m.renameCursor = utf8.RuneCountInString(m.renameInput) // Set cursor at end
+			m.viewMode = ViewRename
+			m.err = nil
+			m.statusMessage = ""
 		}
 
 	case "d":
@@ -542,7 +882,7 @@ func (m Model) updateBrowser(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 					}
 				}
 			}
-			
+
 			if len(filesToDownload) > 0 {
 				m.confirmAction = "download_selected"
 				m.confirmTarget = "" // Not used for batch download
@@ -571,12 +911,176 @@ func (m Model) updateBrowser(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		// Upload file from current directory
 		return m, m.uploadFilePrompt()
 
+	case "i":
+		// Show the metadata of the selected file
+		if m.archive != nil {
+			m.err = fmt.Errorf("cannot inspect files inside archives")
+		} else if len(m.objects) > 0 {
+			selected := m.objects[m.cursor]
+			if selected.IsDir {
+				m.err = fmt.Errorf("cannot inspect directories")
+			} else {
+				m.loading = true
+				m.err = nil
+				m.statusMessage = ""
+				return m, m.inspectObject(selected.Key)
+			}
+		}
+
+	case "O":
+		// Open the selected file with an external program
+		if m.archive != nil {
+			m.err = fmt.Errorf("cannot open files inside archives with external programs")
+		} else if len(m.objects) > 0 {
+			selected := m.objects[m.cursor]
+			if selected.IsDir {
+				m.err = fmt.Errorf("cannot open directories with external programs")
+			} else {
+				m.loading = true
+				m.err = nil
+				m.statusMessage = ""
+				return m, m.openWith(selected.Key)
+			}
+		}
+
+	case "e":
+		// Edit the selected file in $EDITOR and upload the changes
+		if m.archive != nil {
+			m.err = fmt.Errorf("cannot edit files inside archives")
+		} else if len(m.objects) > 0 {
+			selected := m.objects[m.cursor]
+			if selected.IsDir {
+				m.err = fmt.Errorf("cannot edit directories")
+			} else {
+				m.loading = true
+				m.err = nil
+				m.statusMessage = ""
+				return m, m.editObject(selected.Key)
+			}
+		}
+
+	case "M":
+		// Edit the headers and user metadata of the selected items or current file
+		if m.archive != nil {
+			m.err = fmt.Errorf("cannot change metadata of files inside archives")
+		} else if targets := m.batchTargets(); len(targets) > 0 {
+			m.loading = true
+			m.err = nil
+			m.statusMessage = ""
+			return m, m.loadMetadataTargets(targets)
+		}
+
+	case "T":
+		// Edit the tags of the current file, or add or remove a tag on the selection
+		if m.archive != nil {
+			m.err = fmt.Errorf("cannot tag files inside archives")
+		} else if targets := m.batchTargets(); len(targets) == 1 && !strings.HasSuffix(targets[0], "/") {
+			m.loading = true
+			m.err = nil
+			m.statusMessage = ""
+			return m, m.loadTags(targets[0])
+		} else if len(targets) > 0 {
+			m.tagTargets = targets
+			m.openPrompt("tag_selection", "Tag Selection", "Tag to add (key=value) or remove (-key):", "")
+		}
+
+	case "V":
+		// List the versions of the current file, or of everything in the current folder
+		if m.archive != nil {
+			m.err = fmt.Errorf("files inside archives have no versions")
+		} else {
+			prefix := m.currentPath
+			if len(m.objects) > 0 {
+				prefix = m.objects[m.cursor].Key
+			}
+			m.loading = true
+			m.err = nil
+			m.statusMessage = ""
+			return m, m.loadVersions(prefix)
+		}
+
+	case "S":
+		// Share the current file through a presigned URL, or export a manifest of
+		// presigned URLs for the selection or a folder
+		if m.archive != nil {
+			m.err = fmt.Errorf("files inside archives cannot be shared")
+		} else if targets := m.batchTargets(); len(targets) == 1 && !strings.HasSuffix(targets[0], "/") && len(m.selectedFiles) == 0 {
+			m.shareKey = targets[0]
+			m.openPrompt("presign", "Share '"+keyName(targets[0])+"'", "Expiry (e.g. 30m, 12h, 7d); add 'put' for an upload URL:", "1h")
+		} else if len(targets) > 0 {
+			// Deleted objects have nothing to download
+			deleted := make(map[string]bool)
+			for _, obj := range m.objects {
+				deleted[obj.Key] = obj.Deleted
+			}
+			var live []string
+			for _, key := range targets {
+				if !deleted[key] {
+					live = append(live, key)
+				}
+			}
+			if len(live) == 0 {
+				m.err = fmt.Errorf("the selection only holds deleted objects")
+			} else {
+				m.manifestTargets = live
+				m.openPrompt("presign_manifest", "Export Presigned URLs", "Expiry, then the manifest file (.csv, .json or .txt):", "1d "+defaultManifestName(m.bucket, live))
+			}
+		}
+
+	case "C":
+		// Move the selected items or current item to another storage class
+		if m.archive != nil {
+			m.err = fmt.Errorf("files inside archives have no storage class")
+		} else if targets := m.batchTargets(); len(targets) > 0 {
+			class := "STANDARD_IA"
+			if len(m.objects) > 0 && !m.objects[m.cursor].IsDir && m.objects[m.cursor].StorageClass != "" {
+				class = m.objects[m.cursor].StorageClass
+			}
+			m.storageTargets = targets
+			m.openPrompt("storage_class", "Change Storage Class", "Storage class ("+strings.Join(storageClasses, ", ")+"):", class)
+		}
+
+	case "R":
+		// Restore the selected items or current item from GLACIER or DEEP_ARCHIVE
+		if m.archive != nil {
+			m.err = fmt.Errorf("files inside archives cannot be restored")
+		} else if targets := m.batchTargets(); len(targets) > 0 {
+			m.storageTargets = targets
+			m.openPrompt("archive_restore", "Restore From Archive", "Days to keep the restored copy and retrieval tier ("+strings.Join(restoreTiers, ", ")+"):", "7 Standard")
+		}
+
+	case "D":
+		// Show or hide objects whose latest version is a delete marker
+		if m.archive == nil {
+			m.showDeleted = !m.showDeleted
+			m.loading = true
+			m.err = nil
+			m.statusMessage = "Hiding deleted objects"
+			if m.showDeleted {
+				m.statusMessage = "Showing deleted objects (greyed out); U undeletes them"
+			}
+			return m, m.loadObjects()
+		}
+
+	case "U":
+		// Undelete the selected items or current item, folders recursively
+		if m.archive != nil {
+			m.err = fmt.Errorf("files inside archives cannot be undeleted")
+		} else if targets := m.batchTargets(); len(targets) > 0 {
+			m.confirmAction = "undelete_selected"
+			m.confirmTarget = targets[0]
+			m.confirmData = targets
+			m.viewMode = ViewConfirm
+			m.err = nil
+			m.statusMessage = ""
+		}
+
 	case "x":
 		// Delete selected items or current item (with confirmation)
 		if len(m.selectedFiles) > 0 {
 			// Delete all selected items
 			m.confirmAction = "delete_selected"
-			m.confirmTarget = "" // Not used for batch delete
+			m.confirmTarget = ""                                   // Not used for batch delete
 			m.confirmData = append([]string{}, m.selectedFiles...) // Copy selected files
 			m.viewMode = ViewConfirm
 			m.err = nil
@@ -600,50 +1104,66 @@ func (m Model) updateBrowser(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		}
 
 	case "y":
-		// Yank (mark for copying) selected file - toggle behavior
+		// Yank (mark for copying) selected file or folder - toggle behavior
 		if len(m.objects) > 0 {
 			selected := m.objects[m.cursor]
-			if !selected.IsDir {
-				// Check if file is already yanked
-				isYanked := false
-				yankedIndex := -1
-				for i, yankedKey := range m.yankedFiles {
-					if yankedKey == selected.Key {
-						isYanked = true
-						yankedIndex = i
-						break
-					}
-				}
+			key := selected.Key
+			if containsKey(m.yankedFiles, key) && m.yankClient == m.s3Client && m.yankBucket == m.bucket {
+				// Remove from yanked files
+				m.yankedFiles = removeKey(m.yankedFiles, key)
+			} else {
+				m.markSource()
+				m.cutFiles = removeKey(m.cutFiles, key)
+				// Add to yanked files
+				m.yankedFiles = append(m.yankedFiles, key)
+			}
+			m.err = nil
 
-				if isYanked {
-					// Remove from yanked files
-					m.yankedFiles = append(m.yankedFiles[:yankedIndex], m.yankedFiles[yankedIndex+1:]...)
-				} else {
-					// Add to yanked files
-					m.yankedFiles = append(m.yankedFiles, selected.Key)
-				}
-				m.err = nil
+			// Move cursor to next item
+			if m.cursor < len(m.objects)-1 {
+				m.cursor++
+				m.updateScroll()
+			}
+		}
 
-				// Move cursor to next item
-				if m.cursor < len(m.objects)-1 {
-					m.cursor++
-					m.updateScroll()
-				}
+	case "X":
+		// Cut (mark for moving) selected file or folder - toggle behavior
+		if len(m.objects) > 0 {
+			key := m.objects[m.cursor].Key
+			if containsKey(m.cutFiles, key) && m.yankClient == m.s3Client && m.yankBucket == m.bucket {
+				m.cutFiles = removeKey(m.cutFiles, key)
+			} else {
+				m.markSource()
+				m.yankedFiles = removeKey(m.yankedFiles, key)
+				m.cutFiles = append(m.cutFiles, key)
+			}
+			m.err = nil
+
+			// Move cursor to next item
+			if m.cursor < len(m.objects)-1 {
+				m.cursor++
+				m.updateScroll()
 			}
 		}
 
 	case "p":
-		// Paste yanked files to current location
-		if len(m.yankedFiles) > 0 {
-			return m, m.pasteFiles()
+		// Paste yanked files and move cut files to current location
+		if len(m.yankedFiles) > 0 || len(m.cutFiles) > 0 {
+			// Cut items stay marked until the transfer reports them moved
+			cmd := m.pasteFiles()
+			m.loading = true
+			return m, cmd
 		}
 
 	case "c":
-		// Clear all yanked files
-		if len(m.yankedFiles) > 0 {
-			count := len(m.yankedFiles)
+		// Clear all yanked and cut files
+		if len(m.yankedFiles) > 0 || len(m.cutFiles) > 0 {
+			count := len(m.yankedFiles) + len(m.cutFiles)
 			m.yankedFiles = []string{}
-			m.statusMessage = fmt.Sprintf("✓ Cleared %d yanked file(s)", count)
+			m.cutFiles = []string{}
+			m.yankClient = nil
+			m.yankBucket = ""
+			m.statusMessage = fmt.Sprintf("✓ Cleared %d yanked/cut item(s)", count)
 			m.err = nil
 		}
 
@@ -703,7 +1223,7 @@ func (m Model) updateBrowser(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		// Toggle selection of current item
 		if len(m.objects) > 0 {
 			selected := m.objects[m.cursor]
-			
+
 			// Check if item is already selected
 			isSelected := false
 			selectedIndex := -1
@@ -740,6 +1260,20 @@ func (m Model) updateBrowser(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			m.err = nil
 		}
 
+	case "tab":
+		// Switch focus to the other pane, or offer to open one
+		if m.dualPane {
+			m.swapPanes()
+			m.err = nil
+			m.statusMessage = ""
+		} else {
+			m.openPrompt("open_pane", "Open Pane", "Bucket ([profile:]bucket):", "")
+		}
+
+	case "B":
+		// Bind the second pane to another bucket or profile
+		m.openPrompt("open_pane", "Open Pane", "Bucket ([profile:]bucket, empty to close):", "")
+
 	case "?":
 		m.viewMode = ViewHelp
 	}
@@ -747,6 +1281,98 @@ func (m Model) updateBrowser(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	return m, nil
 }
 
+// markSource records the focused pane as the source of yanked and cut items.
+// Marks from another pane are dropped, since a paste reads from a single source.
+func (m *Model) markSource() {
+	if m.yankClient != m.s3Client || m.yankBucket != m.bucket {
+		m.yankedFiles = []string{}
+		m.cutFiles = []string{}
+	}
+	m.yankClient = m.s3Client
+	m.yankBucket = m.bucket
+}
+
+// containsKey reports whether keys contains key
+func containsKey(keys []string, key string) bool {
+	for _, k := range keys {
+		if k == key {
+			return true
+		}
+	}
+	return false
+}
+
+// replaceKey returns a copy of keys with oldKey replaced by newKey, leaving
+// keys untouched as commands may still be reading it
+func replaceKey(keys []string, oldKey, newKey string) []string {
+	if !containsKey(keys, oldKey) {
+		return keys
+	}
+	replaced := make([]string, len(keys))
+	for i, k := range keys {
+		if k == oldKey {
+			k = newKey
+		}
+		replaced[i] = k
+	}
+	return replaced
+}
+
+// removeKey returns keys without key
+func removeKey(keys []string, key string) []string {
+	for i, k := range keys {
+		if k == key {
+			return append(keys[:i:i], keys[i+1:]...)
+		}
+	}
+	return keys
+}
+
+// swapPanes exchanges the focused browser state with the stored other pane
+func (m *Model) swapPanes() {
+	current := paneState{
+		id:            m.paneID,
+		s3Client:      m.s3Client,
+		profile:       m.profile,
+		bucket:        m.bucket,
+		currentPath:   m.currentPath,
+		objects:       m.objects,
+		cursor:        m.cursor,
+		scrollOffset:  m.scrollOffset,
+		selectedFiles: m.selectedFiles,
+		dirStatsCache: m.dirStatsCache,
+		loading:       m.loading,
+	}
+
+	other := m.otherPane
+	m.paneID = other.id
+	m.s3Client = other.s3Client
+	m.profile = other.profile
+	m.bucket = other.bucket
+	m.currentPath = other.currentPath
+	m.objects = other.objects
+	m.cursor = other.cursor
+	m.scrollOffset = other.scrollOffset
+	m.selectedFiles = other.selectedFiles
+	m.dirStatsCache = other.dirStatsCache
+	m.loading = other.loading
+
+	m.otherPane = current
+}
+
+// openPrompt switches to the text prompt for the given action
+func (m *Model) openPrompt(action, title, label, initial string) {
+	m.promptReturn = m.viewMode
+	m.promptAction = action
+	m.promptTitle = title
+	m.promptLabel = label
+	m.promptInput = initial
+	m.promptCursor = utf8.RuneCountInString(initial)
+	m.viewMode = ViewPrompt
+	m.err = nil
+	m.statusMessage = ""
+}
+
 // updateScroll adjusts scroll offset based on cursor position and screen size
 func (m *Model) updateScroll() {
 	if len(m.objects) == 0 {
@@ -785,56 +1411,6 @@ func (m *Model) updateScroll() {
 	}
 }
 
-// updatePreview handles preview view updates
-func (m Model) updatePreview(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
-	switch msg.String() {
-	case "ctrl+c", "q":
-		return m, tea.Quit
-	case "esc", "backspace", "h", "left":
-		m.viewMode = ViewBrowser
-		m.previewContent = ""
-		m.previewFileName = ""
-		m.previewLines = nil
-		m.previewScroll = 0
-		m.previewWidth = 0
-	case "up", "k":
-		if m.previewScroll > 0 {
-			m.previewScroll--
-		}
-	case "down", "j":
-		maxScroll := len(m.previewLines) - (m.height - 8) // Account for title, borders, help
-		if maxScroll < 0 {
-			maxScroll = 0
-		}
-		if m.previewScroll < maxScroll {
-			m.previewScroll++
-		}
-	case "pgup", "u":
-		m.previewScroll -= 10
-		if m.previewScroll < 0 {
-			m.previewScroll = 0
-		}
-	case "pgdown", "d":
-		maxScroll := len(m.previewLines) - (m.height - 8)
-		if maxScroll < 0 {
-			maxScroll = 0
-		}
-		m.previewScroll += 10
-		if m.previewScroll > maxScroll {
-			m.previewScroll = maxScroll
-		}
-	case "home", "g":
-		m.previewScroll = 0
-	case "end", "G":
-		maxScroll := len(m.previewLines) - (m.height - 8)
-		if maxScroll < 0 {
-			maxScroll = 0
-		}
-		m.previewScroll = maxScroll
-	}
-	return m, nil
-}
-
 // updateHelp handles help view updates
 func (m Model) updateHelp(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	switch msg.String() {
@@ -909,7 +1485,7 @@ func (m Model) updateUpload(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 // updateRename handles rename view updates
 func (m Model) updateRename(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	switch msg.String() {
-	case "ctrl+c", "q":
+	case "ctrl+c":
 		return m, tea.Quit
 	case "esc":
 		// Cancel rename
@@ -920,10 +1496,15 @@ func (m Model) updateRename(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		return m, nil
 	case "enter":
 		// Confirm rename
-		if m.renameInput != "" && m.renameInput != filepath.Base(m.renameOriginal) {
+		if m.renameInput != "" && m.renameInput != keyName(m.renameOriginal) {
 			m.viewMode = ViewBrowser
 			m.loading = true
-			cmd := m.renameFile(m.renameOriginal, m.renameInput)
+			var cmd tea.Cmd
+			if strings.HasSuffix(m.renameOriginal, "/") {
+				cmd = m.renameFolder(m.renameOriginal, m.renameInput)
+			} else {
+				cmd = m.renameFile(m.renameOriginal, m.renameInput)
+			}
 			m.renameInput = ""
 			m.renameOriginal = ""
 			m.renameCursor = 0
@@ -935,71 +1516,245 @@ func (m Model) updateRename(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		m.renameOriginal = ""
 		m.renameCursor = 0
 		return m, nil
+	default:
+		m.renameInput, m.renameCursor = editInput(m.renameInput, m.renameCursor, msg)
+	}
+
+	return m, nil
+}
+
+// editInput applies a line-editing key to a text input and returns the new
+// text and cursor. The cursor counts runes, so any UTF-8 text can be edited.
+func editInput(input string, cursor int, msg tea.KeyMsg) (string, int) {
+	text := []rune(input)
+	if cursor > len(text) {
+		cursor = len(text)
+	}
+
+	switch msg.String() {
 	case "backspace":
 		// Remove character to the left of cursor
-		if m.renameCursor > 0 && len(m.renameInput) > 0 {
-			m.renameInput = m.renameInput[:m.renameCursor-1] + m.renameInput[m.renameCursor:]
-			m.renameCursor--
+		if cursor > 0 && len(text) > 0 {
+			text = append(text[:cursor-1], text[cursor:]...)
+			cursor--
 		}
 	case "delete":
 		// Remove character at cursor position
-		if m.renameCursor < len(m.renameInput) {
-			m.renameInput = m.renameInput[:m.renameCursor] + m.renameInput[m.renameCursor+1:]
+		if cursor < len(text) {
+			text = append(text[:cursor], text[cursor+1:]...)
 		}
 	case "left":
 		// Move cursor left
-		if m.renameCursor > 0 {
-			m.renameCursor--
+		if cursor > 0 {
+			cursor--
 		}
 	case "right":
 		// Move cursor right
-		if m.renameCursor < len(m.renameInput) {
-			m.renameCursor++
+		if cursor < len(text) {
+			cursor++
 		}
 	case "home", "ctrl+a":
 		// Go to beginning
-		m.renameCursor = 0
+		cursor = 0
 	case "end", "ctrl+e":
 		// Go to end
-		m.renameCursor = len(m.renameInput)
+		cursor = len(text)
 	case "ctrl+u":
 		// Delete all text to the left of cursor
-		m.renameInput = m.renameInput[m.renameCursor:]
-		m.renameCursor = 0
+		text = text[cursor:]
+		cursor = 0
 	case "ctrl+w":
 		// Delete word to the left of cursor
-		if m.renameCursor > 0 {
+		if cursor > 0 {
 			// Find the start of the current word
-			start := m.renameCursor - 1
+			start := cursor - 1
 			// Skip any trailing spaces
-			for start >= 0 && m.renameInput[start] == ' ' {
+			for start >= 0 && text[start] == ' ' {
 				start--
 			}
-			// Find the beginning of the word
-			for start >= 0 && m.renameInput[start] != ' ' {
-				start--
+			// Find the beginning of the word
+			for start >= 0 && text[start] != ' ' {
+				start--
+			}
+			start++ // Move to the first character of the word
+
+			// Delete from start to cursor
+			text = append(text[:start], text[cursor:]...)
+			cursor = start
+		}
+	default:
+		// Insert typed or pasted characters at cursor position
+		if msg.Type == tea.KeyRunes || msg.Type == tea.KeySpace {
+			inserted := msg.Runes
+			if msg.Type == tea.KeySpace {
+				inserted = []rune{' '}
+			}
+			text = append(text[:cursor], append(append([]rune{}, inserted...), text[cursor:]...)...)
+			cursor += len(inserted)
+		}
+	}
+
+	// Ensure cursor stays within bounds
+	if cursor < 0 {
+		cursor = 0
+	}
+	if cursor > len(text) {
+		cursor = len(text)
+	}
+
+	return string(text), cursor
+}
+
+// updatePrompt handles text prompt view updates
+func (m Model) updatePrompt(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c":
+		return m, tea.Quit
+	case "esc":
+		// Cancel prompt
+		m.viewMode = m.promptReturn
+		m.promptAction = ""
+		m.promptInput = ""
+		m.promptCursor = 0
+		return m, nil
+	case "enter":
+		// Submit prompt
+		action := m.promptAction
+		rawInput := m.promptInput
+		input := strings.TrimSpace(rawInput)
+		m.viewMode = m.promptReturn
+		m.promptAction = ""
+		m.promptInput = ""
+		m.promptCursor = 0
+
+		switch action {
+		case "open_pane":
+			if input == "" {
+				if m.dualPane {
+					m.dualPane = false
+					m.otherPane = paneState{}
+					m.statusMessage = "✓ Closed second pane"
+				}
+				return m, nil
+			}
+			m.statusMessage = "Opening pane..."
+			return m, m.openPane(input)
+		case "hex_jump":
+			offset, err := parseOffset(input, m.previewSize)
+			if err != nil {
+				m.err = err
+				return m, nil
+			}
+			m.err = nil
+			m.hexOffset = offset &^ (hexBytesPerRow - 1)
+			return m, m.ensureHexWindow()
+		case "open_with":
+			if input == "" {
+				return m, nil
+			}
+			m.loading = true
+			return m, m.downloadForViewer(m.openKey, input)
+		case "metadata_value":
+			m.setMetadataField(m.metaFields[m.metaCursor].name, input)
+			return m, nil
+		case "metadata_add":
+			if input == "" {
+				return m, nil
+			}
+			if err := m.addMetadataField(input); err != nil {
+				m.err = err
+			}
+			return m, nil
+		case "tag_value":
+			if err := m.setTag(m.tagFields[m.tagCursor].name, input); err != nil {
+				m.err = err
+			}
+			return m, nil
+		case "tag_add":
+			if input == "" {
+				return m, nil
+			}
+			op, err := parseTagOp(input)
+			if err == nil && op.remove {
+				err = fmt.Errorf("give the tag as key=value; use x to remove tags")
+			}
+			if err == nil {
+				err = m.setTag(op.key, op.value)
+			}
+			m.err = err
+			return m, nil
+		case "tag_selection":
+			if input == "" {
+				return m, nil
+			}
+			op, err := parseTagOp(input)
+			if err != nil {
+				m.err = err
+				return m, nil
+			}
+			m.confirmAction = "tag_selection"
+			m.confirmData = &tagBulk{targets: m.tagTargets, op: op}
+			m.viewMode = ViewConfirm
+			return m, nil
+		case "presign":
+			method, expiry, err := parsePresignOptions(input)
+			if err != nil {
+				m.err = err
+				return m, nil
+			}
+			m.loading = true
+			return m, m.presignObject(m.shareKey, method, expiry)
+		case "storage_class":
+			class, err := parseStorageClass(input)
+			if err != nil {
+				m.err = err
+				return m, nil
+			}
+			m.confirmAction = "storage_class"
+			m.confirmData = &storageChange{targets: m.storageTargets, class: class}
+			m.viewMode = ViewConfirm
+			return m, nil
+		case "archive_restore":
+			days, tier, err := parseRestoreOptions(input)
+			if err != nil {
+				m.err = err
+				return m, nil
 			}
-			start++ // Move to the first character of the word
-
-			// Delete from start to cursor
-			m.renameInput = m.renameInput[:start] + m.renameInput[m.renameCursor:]
-			m.renameCursor = start
+			m.confirmAction = "archive_restore"
+			m.confirmData = &archiveRestore{targets: m.storageTargets, days: days, tier: tier}
+			m.viewMode = ViewConfirm
+			return m, nil
+		case "presign_manifest":
+			path, expiry, err := parseManifestOptions(input)
+			if err != nil {
+				m.err = err
+				return m, nil
+			}
+			m.loading = true
+			m.err = nil
+			m.statusMessage = fmt.Sprintf("Presigning %d item(s)...", len(m.manifestTargets))
+			return m, m.exportManifest(m.manifestTargets, path, expiry)
+		case "version_delete":
+			// Names may start or end with spaces, so the input is compared as typed
+			if rawInput != keyName(m.versionTarget.Key) {
+				m.err = fmt.Errorf("the name typed did not match; nothing was deleted")
+				return m, nil
+			}
+			m.loading = true
+			return m, m.deleteVersion(m.versionTarget)
+		case "search_forward", "search_backward":
+			if input == "" {
+				// An empty pattern repeats the last search
+				input = m.searchText
+			}
+			if input == "" {
+				return m, nil
+			}
+			return m.startSearch(input, action == "search_backward")
 		}
+		return m, nil
 	default:
-		// Add character to input (only printable characters)
-		if len(msg.String()) == 1 && msg.String()[0] >= 32 && msg.String()[0] <= 126 {
-			// Insert character at cursor position
-			m.renameInput = m.renameInput[:m.renameCursor] + msg.String() + m.renameInput[m.renameCursor:]
-			m.renameCursor++
-		}
-	}
-
-	// Ensure cursor stays within bounds
-	if m.renameCursor < 0 {
-		m.renameCursor = 0
-	}
-	if m.renameCursor > len(m.renameInput) {
-		m.renameCursor = len(m.renameInput)
+		m.promptInput, m.promptCursor = editInput(m.promptInput, m.promptCursor, msg)
 	}
 
 	return m, nil
@@ -1012,7 +1767,18 @@ func (m Model) updateConfirm(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		return m, tea.Quit
 	case "esc", "n", "N":
 		// Cancel confirmation
+		if session, ok := m.confirmData.(*editSession); ok && m.confirmAction == "edit_upload" {
+			os.Remove(session.path)
+			m.statusMessage = fmt.Sprintf("Discarded changes to '%s'", displayKey(keyName(session.key)))
+		}
 		m.viewMode = ViewBrowser
+		switch m.confirmAction {
+		case "metadata_apply":
+			// Back to the editor to keep working on the changes
+			m.viewMode = ViewMetadata
+		case "version_restore":
+			m.viewMode = ViewVersions
+		}
 		m.confirmAction = ""
 		m.confirmTarget = ""
 		m.confirmData = nil
@@ -1021,7 +1787,7 @@ func (m Model) updateConfirm(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		// Confirm action
 		m.viewMode = ViewBrowser
 		m.loading = true
-		
+
 		var cmd tea.Cmd
 		switch m.confirmAction {
 		case "delete":
@@ -1034,6 +1800,10 @@ func (m Model) updateConfirm(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			}
 		case "download":
 			cmd = m.downloadFile(m.confirmTarget)
+		case "extract":
+			if entry, ok := m.archive.entry(m.confirmTarget); ok {
+				cmd = m.extractArchiveEntry(entry)
+			}
 		case "download_selected":
 			if selectedFiles, ok := m.confirmData.([]string); ok {
 				cmd = m.downloadSelectedItems(selectedFiles)
@@ -1042,13 +1812,44 @@ func (m Model) updateConfirm(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			if fullPath, ok := m.confirmData.(string); ok {
 				cmd = m.uploadFile(fullPath)
 			}
+		case "edit_upload":
+			if session, ok := m.confirmData.(*editSession); ok {
+				cmd = m.uploadEdits(session)
+			}
+		case "tag_selection":
+			if bulk, ok := m.confirmData.(*tagBulk); ok {
+				cmd = m.retagObjects(bulk)
+			}
+		case "undelete_selected":
+			if targets, ok := m.confirmData.([]string); ok {
+				cmd = m.undeleteObjects(targets)
+			}
+		case "storage_class":
+			if change, ok := m.confirmData.(*storageChange); ok {
+				cmd = m.changeStorageClass(change)
+			}
+		case "archive_restore":
+			if restore, ok := m.confirmData.(*archiveRestore); ok {
+				cmd = m.restoreArchived(restore)
+			}
+		case "version_restore":
+			if v, ok := m.confirmData.(ObjectVersion); ok {
+				m.viewMode = ViewVersions
+				cmd = m.restoreVersion(v)
+			}
+		case "metadata_apply":
+			if edit, ok := m.confirmData.(*metadataEdit); ok {
+				m.metaFields = nil
+				m.metaOriginal = nil
+				cmd = m.rewriteMetadata(edit)
+			}
 		}
-		
+
 		// Clear confirmation state
 		m.confirmAction = ""
 		m.confirmTarget = ""
 		m.confirmData = nil
-		
+
 		return m, cmd
 	}
 	return m, nil
@@ -1069,6 +1870,22 @@ func (m Model) View() string {
 		return m.viewRename()
 	case ViewConfirm:
 		return m.viewConfirm()
+	case ViewPrompt:
+		return m.viewPrompt()
+	case ViewProgress:
+		return m.viewProgress()
+	case ViewInfo:
+		return m.viewInfo()
+	case ViewMetadata:
+		return m.viewMetadata()
+	case ViewReport:
+		return m.viewReport()
+	case ViewTags:
+		return m.viewTags()
+	case ViewVersions:
+		return m.viewVersions()
+	case ViewShare:
+		return m.viewShare()
 	}
 	return ""
 }
@@ -1079,14 +1896,31 @@ func (m Model) viewBrowser() string {
 
 	// Title
 	title := fmt.Sprintf("Bucket: %s", m.bucket)
-	if m.currentPath != "" {
-		title += fmt.Sprintf(" | Path: /%s", m.currentPath)
+	if m.dualPane {
+		title = fmt.Sprintf("[%d] Bucket: %s (%s)", m.paneID+1, m.bucket, m.profile)
+	}
+	if m.archive != nil {
+		title += fmt.Sprintf(" | Archive: /%s/%s", displayKey(m.archive.key), displayKey(m.archivePath))
+	} else if m.currentPath != "" {
+		title += fmt.Sprintf(" | Path: /%s", displayKey(m.currentPath))
 	}
 	if len(m.selectedFiles) > 0 {
 		title += fmt.Sprintf(" | Selected: %d item(s)", len(m.selectedFiles))
 	}
+	if m.showDeleted {
+		title += " | Showing deleted"
+	}
 	if len(m.yankedFiles) > 0 {
-		title += fmt.Sprintf(" | Yanked: %d file(s)", len(m.yankedFiles))
+		title += fmt.Sprintf(" | Yanked: %d item(s)", len(m.yankedFiles))
+		if m.yankBucket != m.bucket || m.yankClient != m.s3Client {
+			title += fmt.Sprintf(" from %s", m.yankBucket)
+		}
+	}
+	if len(m.cutFiles) > 0 {
+		title += fmt.Sprintf(" | Cut: %d item(s)", len(m.cutFiles))
+		if m.yankBucket != m.bucket || m.yankClient != m.s3Client {
+			title += fmt.Sprintf(" from %s", m.yankBucket)
+		}
 	}
 	s.WriteString(titleStyle.Render(title))
 	s.WriteString("\n\n")
@@ -1125,14 +1959,17 @@ func (m Model) viewBrowser() string {
 			}
 
 			// Calculate dynamic filename width based on terminal width
-			maxSizeWidth := 8  // constant width for size column
-			dateWidth := 19    // constant width for date column (YYYY-MM-DD HH:MM:SS)
-			
+			maxSizeWidth := 8 // constant width for size column
+			dateWidth := 19   // constant width for date column (YYYY-MM-DD HH:MM:SS)
+
 			// Calculate available space for filename column
-			// Account for: cursor (2), selection indicator (2), yank indicator (2), spaces between columns (8), size column (8), date column (19)
-			usedWidth := 2 + 2 + 2 + 8 + maxSizeWidth + dateWidth
+			// Account for: cursor (2), selection indicator (2), yank indicator (2), spaces between columns (9), size column (8), storage class column (19), date column (19)
+			usedWidth := 2 + 2 + 2 + 9 + maxSizeWidth + storageClassWidth + dateWidth
 			availableWidth := m.width - usedWidth - 10 // Extra margin for borders and centering
-			
+			if m.dualPane {
+				availableWidth -= m.sidePaneWidth()
+			}
+
 			// Set reasonable bounds for filename width
 			maxNameWidth := availableWidth
 			if maxNameWidth < 15 {
@@ -1150,16 +1987,8 @@ func (m Model) viewBrowser() string {
 					cursor = ">"
 				}
 
-				name := filepath.Base(obj.Key)
-				if obj.IsDir {
-					name += "/"
-				}
-				
 				// Always truncate name to fit dynamic width
-				displayName := name
-				if len(name) > maxNameWidth {
-					displayName = name[:maxNameWidth-3] + "..."
-				}
+				name := truncateString(displayName(obj), maxNameWidth)
 
 				// Check if item is selected
 				// Always reserve space for selection indicator to maintain consistent alignment
@@ -1171,25 +2000,28 @@ func (m Model) viewBrowser() string {
 					}
 				}
 
-				// Check if file is yanked (directories can't be yanked)
+				// Check if item is yanked (only when yanks come from this pane)
 				// Always reserve space for yank indicator to maintain consistent alignment
 				yankedIndicator := " " // Default: empty space
-				if !obj.IsDir {
-					for _, yankedKey := range m.yankedFiles {
-						if obj.Key == yankedKey {
-							yankedIndicator = lipgloss.NewStyle().Foreground(lipgloss.Color("#ffff00")).Render("●")
-							break
-						}
+				if m.yankClient == m.s3Client && m.yankBucket == m.bucket {
+					if containsKey(m.yankedFiles, obj.Key) {
+						yankedIndicator = lipgloss.NewStyle().Foreground(lipgloss.Color("#ffff00")).Render("●")
+					} else if containsKey(m.cutFiles, obj.Key) {
+						yankedIndicator = lipgloss.NewStyle().Foreground(lipgloss.Color("#cc00cc")).Render("✂")
 					}
 				}
 
 				// Format with consistent column alignment for both files and directories
-				paddedName := fmt.Sprintf("%-*s", maxNameWidth, displayName)
-				
+				paddedName := padRight(name, maxNameWidth)
+
 				var paddedSize string
 				var displayDate string
-				
-				if obj.IsDir {
+
+				if obj.Deleted {
+					// Deleted objects and folders holding only deleted objects have no live size
+					paddedSize = fmt.Sprintf("%*s", maxSizeWidth, "-")
+					displayDate = obj.LastModified
+				} else if obj.IsDir {
 					// Check if we have cached directory stats
 					if stats, exists := m.dirStatsCache[obj.Key]; exists {
 						if stats.SizeTimeout {
@@ -1198,7 +2030,7 @@ func (m Model) viewBrowser() string {
 							size := formatSize(stats.Size)
 							paddedSize = fmt.Sprintf("%*s", maxSizeWidth, size)
 						}
-						
+
 						if stats.DateTimeout {
 							displayDate = "N/A"
 						} else {
@@ -1218,14 +2050,25 @@ func (m Model) viewBrowser() string {
 
 				// Apply styling based on type
 				var styledName string
-				if obj.IsDir {
+				if obj.Deleted {
+					styledName = ghostStyle.Render(paddedName)
+				} else if obj.IsDir {
 					styledName = directoryStyle.Render(paddedName)
 				} else {
 					styledName = fileStyle.Render(paddedName)
 				}
 
+				// Storage class of files, with the restore state of archived ones
+				storageClass := strings.Repeat(" ", storageClassWidth)
+				if !obj.IsDir && !obj.Deleted {
+					storageClass = padRight(storageColumn(obj), storageClassWidth)
+					if archivedReadError(obj) != nil {
+						storageClass = archivedStyle.Render(storageClass)
+					}
+				}
+
 				// Use consistent format for all items (always has selection and yank indicator spaces reserved)
-				line := fmt.Sprintf("%s %s %s %s %s %s", cursor, selectedIndicator, yankedIndicator, styledName, paddedSize, displayDate)
+				line := fmt.Sprintf("%s %s %s %s %s %s %s", cursor, selectedIndicator, yankedIndicator, styledName, paddedSize, storageClass, displayDate)
 
 				if i == m.cursor {
 					line = selectedStyle.Render(line)
@@ -1251,6 +2094,9 @@ func (m Model) viewBrowser() string {
 	// Wrap content in border and center it
 	content := s.String()
 	bordered := browserStyle.Render(content)
+	if m.dualPane {
+		bordered = lipgloss.JoinHorizontal(lipgloss.Top, bordered, m.viewOtherPane())
+	}
 
 	// Center the content on screen
 	if m.width > 0 && m.height > 0 {
@@ -1260,69 +2106,68 @@ func (m Model) viewBrowser() string {
 	return bordered
 }
 
-// viewPreview renders the file preview view
-func (m Model) viewPreview() string {
+// sidePaneWidth returns the width of the unfocused pane column
+func (m Model) sidePaneWidth() int {
+	width := m.width / 3
+	if width < 24 {
+		width = 24
+	}
+	return width
+}
+
+// viewOtherPane renders a compact listing of the unfocused pane
+func (m Model) viewOtherPane() string {
 	var s strings.Builder
+	p := m.otherPane
+	width := m.sidePaneWidth() - 6
 
-	title := fmt.Sprintf("Preview: %s", m.previewFileName)
-	s.WriteString(titleStyle.Render(title))
+	title := fmt.Sprintf("[%d] %s (%s)", p.id+1, p.bucket, p.profile)
+	s.WriteString(helpStyle.Render(truncateString(title, width)))
+	s.WriteString("\n")
+	s.WriteString(helpStyle.Render(truncateString("/"+displayKey(p.currentPath), width)))
 	s.WriteString("\n\n")
 
-	if m.err != nil {
-		s.WriteString(errorStyle.Render(fmt.Sprintf("Error: %s", m.err.Error())))
+	if p.loading {
+		s.WriteString(helpStyle.Render("Loading..."))
+	} else if len(p.objects) == 0 {
+		s.WriteString(helpStyle.Render("(empty)"))
 	} else {
-		// Calculate visible lines
-		visibleHeight := m.height - 8 // Account for title, borders, help
-		if visibleHeight < 1 {
-			visibleHeight = 10
-		}
-
-		var visibleLines []string
-		totalLines := len(m.previewLines)
-
-		if totalLines == 0 {
-			visibleLines = []string{"[Empty file]"}
-		} else {
-			start := m.previewScroll
-			end := start + visibleHeight
-			if end > totalLines {
-				end = totalLines
-			}
-			if start < totalLines {
-				visibleLines = m.previewLines[start:end]
+		availableHeight := m.height - 8
+		if availableHeight < 5 {
+			availableHeight = 5
+		}
+		start := p.scrollOffset
+		end := start + availableHeight
+		if end > len(p.objects) {
+			end = len(p.objects)
+		}
+		for i := start; i < end; i++ {
+			obj := p.objects[i]
+			name := displayName(obj)
+			cursor := "  "
+			if i == p.cursor {
+				cursor = "> "
+			}
+			line := truncateString(cursor+name, width)
+			if obj.IsDir {
+				s.WriteString(directoryStyle.Render(line))
+			} else {
+				s.WriteString(fileStyle.Render(line))
 			}
+			s.WriteString("\n")
 		}
-
-		// Add line numbers and content
-		var contentBuilder strings.Builder
-		for i, line := range visibleLines {
-			lineNum := m.previewScroll + i + 1
-			contentBuilder.WriteString(fmt.Sprintf("%4d │ %s\n", lineNum, line))
-		}
-
-		// Show scroll indicator if needed
-		if totalLines > visibleHeight {
-			contentBuilder.WriteString(fmt.Sprintf("\n[Showing lines %d-%d of %d]",
-				m.previewScroll+1,
-				m.previewScroll+len(visibleLines),
-				totalLines))
-		}
-
-		// Create a preview style with calculated width
-		previewStyleWithWidth := previewStyle.Width(m.previewWidth - 8) // Account for padding and borders
-		s.WriteString(previewStyleWithWidth.Render(contentBuilder.String()))
 	}
 
-	s.WriteString("\n\n")
-	s.WriteString(helpStyle.Render("↑/k,↓/j: scroll • u/d: page up/down • g/G: top/bottom • ←/h/esc: back • q: quit"))
+	s.WriteString("\n")
+	s.WriteString(helpStyle.Render("tab: switch pane"))
 
-	// Center the preview content
-	content := s.String()
-	if m.width > 0 && m.height > 0 {
-		centered := centerStyle.Width(m.width).Render(content)
-		return verticalCenterStyle.Height(m.height).Render(centered)
-	}
-	return content
+	paneStyle := lipgloss.NewStyle().
+		Border(lipgloss.NormalBorder()).
+		BorderForeground(lipgloss.Color("#555555")).
+		Padding(1, 2).
+		Width(m.sidePaneWidth())
+
+	return paneStyle.Render(s.String())
 }
 
 // viewHelp renders the help view
@@ -1341,6 +2186,8 @@ func (m Model) viewHelp() string {
   G           Go to last item
   ←/h         Go back to parent directory
   →/l/o/enter Enter directory or preview file
+              (.zip, .tar, .tar.gz and .tgz open as folders; inside an
+              archive, d extracts an entry and esc leaves it)
 
 Actions:
   ?           Show this help
@@ -1350,16 +2197,82 @@ File Operations:
   enter/l/o   Preview text files or enter directories
   d           Download selected file to current directory
   u           Upload file from current directory
+  i           Show metadata of the selected file (headers, storage
+              class, encryption, version, object lock, replication,
+              user metadata); y copies a value, Y all of them
+  O           Open selected file with an external program (handlers
+              by Content-Type or name from .s4cfg, else $PAGER for
+              text and xdg-open/open for the rest)
+  e           Edit selected file in $EDITOR; the changes are shown
+              as a diff and uploaded only if nobody else changed it
+  M           Edit Content-Type, Cache-Control and other headers and
+              user metadata of the selected files and folders (or the
+              current file); changes are applied to every object by a
+              copy onto itself and summed up in a report
+  T           Edit the tags of the current file; with a selection or
+              on a folder, add (key=value) or remove (-key) one tag
+              on every object
+  V           Show the versions and delete markers of the selected
+              file or folder (versioned buckets): preview or download
+              a version, r restores it, u undeletes, X deletes one
+              forever after typing the file name
+  S           Share the selected file with a presigned GET (or PUT)
+              URL valid for a chosen time (up to 7d); the URL is
+              copied to the clipboard, Q shows it as a QR code. With
+              a selection or on a folder, write presigned GET URLs of
+              every object below it to a .csv, .json or .txt manifest
+  D           Show or hide deleted files and folders (versioned
+              buckets), greyed out; enter on one lists its versions
+  C           Move the selected files and folders (recursively) or
+              the current file to another storage class
+  R           Restore the selected archived (GLACIER, DEEP_ARCHIVE)
+              files and folders for a number of days, with a chosen
+              retrieval tier; the storage class column shows
+              "restoring" and then until when the copy is readable
+  U           Undelete the selected files and folders (recursively)
+              or the current one by removing their delete markers
   x           Delete selected file from S3
-  y           Yank (mark) selected file for copying (toggle)
-  p           Paste all yanked files to current location
-  c           Clear all yanked files
-  r           Rename selected file
+  y           Yank (mark) selected file or folder for copying (toggle)
+  X           Cut (mark) selected file or folder for moving (toggle)
+  p           Paste yanked items and move cut items to current location
+              (folders recursively; sources are deleted only after the
+              copy is confirmed by HEAD/ETag)
+  c           Clear all yanked and cut items
+  r           Rename selected file or folder
+
+Panes:
+  tab         Switch to the other pane (opens one if needed)
+  B           Bind the other pane to [profile:]bucket
+  p           Paste files yanked in the other pane (server-side when
+              both panes share an endpoint, streamed otherwise)
 
 Preview Navigation:
   ↑/k,↓/j     Scroll line by line
   u/d         Page up/down (10 lines)
-  g/G         Jump to top/bottom
+  g/G         Jump to head/tail of the object (large objects are
+              read in ranges as you scroll)
+  p           Toggle pretty-printing of JSON and XML (objects up to
+              4 MiB)
+  (gzip, zstd, bzip2 and xz objects are decompressed as they are read;
+   the title shows the compressed and decompressed sizes)
+  t           Toggle table view of CSV, TSV and JSON Lines (on by
+              default; ←/h →/l scroll columns, 0/$ first/last column,
+              c picks the columns to show)
+  (previewers configured in .s4cfg replace the preview of matching
+   objects with the output of a command)
+  (Parquet and Avro files show their schema, row group or block
+   statistics and codec; t shows their first rows as a table)
+  ←/h,→/l     Scroll long lines sideways (h at the first column
+              returns to the browser); 0/$ first/last column
+  w           Toggle wrapping of long lines
+  F           Follow the object like tail -f: poll for appended
+              data, reload the tail if the object is replaced
+  /,?         Search forward/backward for a regular expression
+              (case-insensitive unless it has capitals; objects
+              beyond the loaded range are scanned, esc cancels)
+  n/N         Jump to the next/previous match
+  x           Toggle hex dump (binary objects always open as hex)
+  :           Jump to offset in hex dump (decimal, 0x hex, -N from end)
   ←/h/esc     Return to browser
   
 Browser Features:
@@ -1367,11 +2280,12 @@ Browser Features:
   - Preview text files in-place
   - Download files to local directory
   - Upload files from local directory
-  - Copy/paste files within the bucket
-  - Rename files with interactive popup
+  - Copy/paste files and whole folders, verified after the copy
+  - Rename files and folders with interactive popup
   - Shows file sizes and modification dates
   - Distinguishes directories from files
   - Automatic name conflict resolution (adds _copy_N suffix)
+  - Second pane for copying between buckets and profiles
 
 Configuration:
   S4 reads configuration from .s3cfg file in:
@@ -1405,7 +2319,7 @@ func (m Model) viewUpload() string {
 
 	title := fmt.Sprintf("Local: %s", displayPath)
 	if m.currentPath != "" {
-		title += fmt.Sprintf(" → S3: /%s", m.currentPath)
+		title += fmt.Sprintf(" → S3: /%s", displayKey(m.currentPath))
 	} else {
 		title += " → S3: /"
 	}
@@ -1466,7 +2380,7 @@ func (m Model) viewUpload() string {
 func (m Model) viewRename() string {
 	var s strings.Builder
 
-	title := fmt.Sprintf("Rename: %s", filepath.Base(m.renameOriginal))
+	title := fmt.Sprintf("Rename: %s", displayKey(keyName(m.renameOriginal)))
 	s.WriteString(titleStyle.Render(title))
 	s.WriteString("\n\n")
 
@@ -1522,8 +2436,8 @@ func (m Model) viewConfirm() string {
 
 	// Create title based on action
 	var title, message string
-	filename := filepath.Base(m.confirmTarget)
-	
+	filename := displayKey(keyName(m.confirmTarget))
+
 	switch m.confirmAction {
 	case "delete":
 		title = "Confirm Delete"
@@ -1546,6 +2460,9 @@ func (m Model) viewConfirm() string {
 	case "download":
 		title = "Confirm Download"
 		message = fmt.Sprintf("Download '%s' to current directory?", filename)
+	case "extract":
+		title = "Confirm Extract"
+		message = fmt.Sprintf("Extract '%s' from '%s' to current directory?", filename, displayKey(keyName(m.archive.key)))
 	case "download_selected":
 		title = "Confirm Download Selected Items"
 		if selectedFiles, ok := m.confirmData.([]string); ok {
@@ -1561,10 +2478,59 @@ func (m Model) viewConfirm() string {
 	case "upload":
 		title = "Confirm Upload"
 		if m.currentPath != "" {
-			message = fmt.Sprintf("Upload '%s' to S3 path '/%s'?", filename, m.currentPath)
+			message = fmt.Sprintf("Upload '%s' to S3 path '/%s'?", filename, displayKey(m.currentPath))
 		} else {
 			message = fmt.Sprintf("Upload '%s' to S3 root?", filename)
 		}
+	case "edit_upload":
+		title = "Confirm Upload of Changes"
+		message = fmt.Sprintf("Upload your changes to '%s'?\n\nThe upload fails if the object changed since it was opened.", filename)
+		if session, ok := m.confirmData.(*editSession); ok {
+			// The diff keeps its own alignment inside the centred popup
+			message += "\n\n" + lipgloss.NewStyle().Align(lipgloss.Left).Render(editConfirmMessage(session))
+		}
+	case "undelete_selected":
+		title = "Confirm Undelete"
+		if targets, ok := m.confirmData.([]string); ok {
+			if len(targets) == 1 && !strings.HasSuffix(targets[0], "/") {
+				message = fmt.Sprintf("Undelete '%s'?", filename)
+			} else {
+				message = fmt.Sprintf("Undelete %d selected item(s)?\n\nEvery deleted object below the selected folders is brought back too.", len(targets))
+			}
+			message += "\n\nDelete markers are removed, so each object returns to the version it had before it was deleted."
+		}
+	case "version_restore":
+		title = "Confirm Restore"
+		if v, ok := m.confirmData.(ObjectVersion); ok {
+			message = fmt.Sprintf("Make version %s of '%s' (%s, %s) the current one?\n\nIt is copied over the object; the current version stays in the history.",
+				shortVersion(v.VersionID), filename, formatSize(v.Size), v.LastModified.Local().Format("2006-01-02 15:04:05"))
+		}
+	case "tag_selection":
+		title = "Confirm Tagging"
+		if bulk, ok := m.confirmData.(*tagBulk); ok {
+			message = fmt.Sprintf("Do you want to %s on %d selected item(s)?\n\nFolders include every object below them.", bulk.op.describe(), len(bulk.targets))
+		}
+	case "storage_class":
+		title = "Confirm Storage Class Change"
+		if change, ok := m.confirmData.(*storageChange); ok {
+			message = storageClassConfirmMessage(change)
+		}
+	case "archive_restore":
+		title = "Confirm Restore From Archive"
+		if restore, ok := m.confirmData.(*archiveRestore); ok {
+			message = restoreConfirmMessage(restore)
+		}
+	case "metadata_apply":
+		title = "Confirm Metadata Changes"
+		if edit, ok := m.confirmData.(*metadataEdit); ok {
+			if len(edit.keys) == 1 {
+				message = fmt.Sprintf("Rewrite the metadata of '%s'?", filename)
+			} else {
+				message = fmt.Sprintf("Rewrite the metadata of %d objects?\n\nOnly these fields are changed; each object keeps its other headers.", len(edit.keys))
+			}
+			message += "\n\nObjects are copied onto themselves, which creates a new version in versioned buckets."
+			message += "\n\n" + lipgloss.NewStyle().Align(lipgloss.Left).Render(metadataConfirmMessage(edit))
+		}
 	default:
 		title = "Confirm Action"
 		message = "Are you sure?"
@@ -1588,14 +2554,14 @@ func (m Model) viewConfirm() string {
 
 	// Wrap content and center it
 	content := s.String()
-	
+
 	// Create a popup-style border (orange color for attention)
 	popupStyle := lipgloss.NewStyle().
 		Border(lipgloss.DoubleBorder()).
 		BorderForeground(lipgloss.Color("#cc6600")).
 		Padding(2, 4).
 		Align(lipgloss.Center)
-	
+
 	popup := popupStyle.Render(content)
 
 	// Center the popup on screen
@@ -1606,51 +2572,135 @@ func (m Model) viewConfirm() string {
 	return popup
 }
 
-// renderInputWithCursor renders the input text with a visible cursor
-func (m Model) renderInputWithCursor() string {
-	if len(m.renameInput) == 0 {
-		// Empty input, show cursor at beginning
-		return "█"
+// viewPrompt renders the text prompt popup view
+func (m Model) viewPrompt() string {
+	var s strings.Builder
+
+	s.WriteString(titleStyle.Render(m.promptTitle))
+	s.WriteString("\n\n")
+
+	// Error display
+	if m.err != nil {
+		s.WriteString(errorStyle.Render(fmt.Sprintf("Error: %s", m.err.Error())))
+		s.WriteString("\n\n")
 	}
 
-	// Insert cursor character at cursor position
-	before := m.renameInput[:m.renameCursor]
-	after := m.renameInput[m.renameCursor:]
+	s.WriteString(m.promptLabel)
+	s.WriteString("\n")
+
+	inputStyle := lipgloss.NewStyle().
+		Border(lipgloss.NormalBorder()).
+		BorderForeground(lipgloss.Color("#0066cc")).
+		Padding(0, 1).
+		Width(40)
+
+	s.WriteString(inputStyle.Render(renderTextInput(m.promptInput, m.promptCursor)))
+	s.WriteString("\n\n")
 
-	// Use a block cursor character
-	cursor := "█"
+	s.WriteString(helpStyle.Render("enter: confirm • esc: cancel • ←/→: move cursor • ctrl+a/e: start/end • ctrl+u: clear left • ctrl+w: delete word"))
 
-	// If cursor is at the end, append cursor
-	if m.renameCursor >= len(m.renameInput) {
-		return m.renameInput + cursor
+	popupStyle := lipgloss.NewStyle().
+		Border(lipgloss.DoubleBorder()).
+		BorderForeground(lipgloss.Color("#0066cc")).
+		Padding(2, 4).
+		Align(lipgloss.Center)
+
+	popup := popupStyle.Render(s.String())
+
+	// Center the popup on screen
+	if m.width > 0 && m.height > 0 {
+		centered := centerStyle.Width(m.width).Render(popup)
+		return verticalCenterStyle.Height(m.height).Render(centered)
+	}
+	return popup
+}
+
+// viewProgress renders the progress of a running transfer
+func (m Model) viewProgress() string {
+	var s strings.Builder
+
+	s.WriteString(titleStyle.Render(m.progressTitle))
+	s.WriteString("\n\n")
+
+	// Progress bar
+	barWidth := 40
+	filled := 0
+	if m.progressTotal > 0 {
+		filled = barWidth * m.progressDone / m.progressTotal
+	}
+	bar := strings.Repeat("█", filled) + strings.Repeat("░", barWidth-filled)
+	s.WriteString(fmt.Sprintf("%s %d/%d", bar, m.progressDone, m.progressTotal))
+	s.WriteString("\n\n")
+
+	if m.progressVerify {
+		s.WriteString("Verifying destination keys...")
+	} else {
+		s.WriteString(truncateString(m.progressCurrent, 60))
+	}
+	s.WriteString("\n\n")
+	s.WriteString(helpStyle.Render("ctrl+c: quit"))
+
+	popupStyle := lipgloss.NewStyle().
+		Border(lipgloss.DoubleBorder()).
+		BorderForeground(lipgloss.Color("#0066cc")).
+		Padding(2, 4).
+		Align(lipgloss.Center)
+
+	popup := popupStyle.Render(s.String())
+
+	// Center the popup on screen
+	if m.width > 0 && m.height > 0 {
+		centered := centerStyle.Width(m.width).Render(popup)
+		return verticalCenterStyle.Height(m.height).Render(centered)
 	}
+	return popup
+}
+
+// renderInputWithCursor renders the rename input text with a visible cursor
+func (m Model) renderInputWithCursor() string {
+	return renderTextInput(m.renameInput, m.renameCursor)
+}
 
-	// If cursor is in the middle, replace the character at cursor position with highlighted version
-	if m.renameCursor < len(m.renameInput) {
-		// Create a highlighted version of the character under cursor
-		charUnderCursor := string(m.renameInput[m.renameCursor])
-		highlightedChar := lipgloss.NewStyle().
-			Background(lipgloss.Color("#ffffff")).
-			Foreground(lipgloss.Color("#000000")).
-			Render(charUnderCursor)
+// renderTextInput renders input text with a visible cursor. Non-printable
+// characters are shown escaped so they cannot corrupt the terminal.
+func renderTextInput(input string, cursorPos int) string {
+	text := []rune(input)
+	if len(text) == 0 {
+		// Empty input, show cursor at beginning
+		return "█"
+	}
 
-		return before + highlightedChar + after[1:]
+	// If cursor is at the end, append cursor
+	if cursorPos >= len(text) {
+		return displayKey(input) + "█"
 	}
 
-	return before + cursor + after
+	// Otherwise highlight the character under the cursor
+	before := displayKey(string(text[:cursorPos]))
+	after := displayKey(string(text[cursorPos+1:]))
+	highlightedChar := lipgloss.NewStyle().
+		Background(lipgloss.Color("#ffffff")).
+		Foreground(lipgloss.Color("#000000")).
+		Render(displayKey(string(text[cursorPos])))
+
+	return before + highlightedChar + after
 }
 
 // loadObjects loads objects from S3
 func (m Model) loadObjects() tea.Cmd {
+	pane := m.paneID
+	showDeleted := m.showDeleted
 	return tea.Cmd(func() tea.Msg {
-		prefix := m.currentPath
-		if prefix != "" && !strings.HasSuffix(prefix, "/") {
-			prefix += "/"
-		}
-
-		objects, err := m.s3Client.ListObjects(context.Background(), m.bucket, prefix)
+		objects, err := m.s3Client.ListObjects(context.Background(), m.bucket, m.currentPath)
 		if err != nil {
-			return objectsLoadedMsg{err: err}
+			return objectsLoadedMsg{pane: pane, err: err}
+		}
+		if showDeleted {
+			deleted, err := m.s3Client.ListDeletedObjects(context.Background(), m.bucket, m.currentPath)
+			if err != nil {
+				return objectsLoadedMsg{pane: pane, err: err}
+			}
+			objects = mergeDeleted(objects, deleted)
 		}
 
 		// Sort objects: directories first, then files, both alphabetically
@@ -1661,30 +2711,7 @@ func (m Model) loadObjects() tea.Cmd {
 			return objects[i].Key < objects[j].Key
 		})
 
-		return objectsLoadedMsg{objects: objects}
-	})
-}
-
-// previewFileContent loads file content for preview
-func (m Model) previewFileContent(key string) tea.Cmd {
-	return tea.Cmd(func() tea.Msg {
-		data, err := m.s3Client.GetObject(context.Background(), m.bucket, key)
-		if err != nil {
-			return previewLoadedMsg{err: err}
-		}
-
-		// Check if content is text (simple heuristic)
-		if !utf8.Valid(data) {
-			return previewLoadedMsg{
-				content: "[Binary file - cannot preview]",
-				file:    key,
-			}
-		}
-
-		return previewLoadedMsg{
-			content: string(data),
-			file:    key,
-		}
+		return objectsLoadedMsg{pane: pane, objects: objects}
 	})
 }
 
@@ -1752,10 +2779,7 @@ func (m Model) uploadFile(fullPath string) tea.Cmd {
 		filename := filepath.Base(fullPath)
 
 		// Construct S3 key
-		key := filename
-		if m.currentPath != "" {
-			key = m.currentPath + "/" + filename
-		}
+		key := m.currentPath + filename
 
 		err = m.s3Client.PutObject(context.Background(), m.bucket, key, data)
 		if err != nil {
@@ -1775,7 +2799,7 @@ func (m Model) deleteFile(key string) tea.Cmd {
 		}
 
 		// Get just the filename for display
-		filename := filepath.Base(key)
+		filename := displayKey(keyName(key))
 		return fileDeletedMsg{filename: filename}
 	})
 }
@@ -1875,14 +2899,14 @@ func (m Model) deleteFolder(folderKey string) tea.Cmd {
 			err := m.s3Client.DeleteObject(context.Background(), m.bucket, fileKey)
 			if err != nil {
 				return folderDeletedMsg{
-					err: fmt.Errorf("failed to delete '%s': %w", filepath.Base(fileKey), err),
+					err: fmt.Errorf("failed to delete '%s': %w", displayKey(keyName(fileKey)), err),
 				}
 			}
 			deletedCount++
 		}
 
 		// Get just the folder name for display
-		foldername := filepath.Base(folderKey)
+		foldername := displayKey(keyName(folderKey))
 		return folderDeletedMsg{
 			foldername:   foldername,
 			deletedCount: deletedCount,
@@ -1906,8 +2930,8 @@ func (m Model) downloadSelectedItems(selectedKeys []string) tea.Cmd {
 				continue
 			}
 
-			// Get just the filename from the key
-			filename := filepath.Base(key)
+			// Get a local filename from the key that cannot escape the current directory
+			filename := localFileName(key)
 
 			// Write to local file
 			err = os.WriteFile(filename, data, 0644)
@@ -1936,8 +2960,8 @@ func (m Model) downloadFile(key string) tea.Cmd {
 			return fileDownloadedMsg{err: err}
 		}
 
-		// Get just the filename from the key
-		filename := filepath.Base(key)
+		// Get a local filename from the key that cannot escape the current directory
+		filename := localFileName(key)
 
 		// Write to local file
 		err = os.WriteFile(filename, data, 0644)
@@ -1949,56 +2973,11 @@ func (m Model) downloadFile(key string) tea.Cmd {
 	})
 }
 
-// calculatePreviewWidth calculates the optimal width for the preview window
-func (m Model) calculatePreviewWidth() int {
-	if len(m.previewLines) == 0 {
-		return 80 // Default width
-	}
-
-	maxLineLength := 0
-	for _, line := range m.previewLines {
-		// Use rune count for proper Unicode handling, account for line numbers (4 digits + " │ ")
-		lineLength := utf8.RuneCountInString(line) + 6
-		if lineLength > maxLineLength {
-			maxLineLength = lineLength
-		}
-	}
-
-	// Add padding for borders and content padding (4 chars for borders + 4 for padding)
-	optimalWidth := maxLineLength + 8
-
-	// Limit to terminal width minus some margin
-	maxAllowedWidth := m.width - 10
-	if maxAllowedWidth < 40 {
-		maxAllowedWidth = 40 // Minimum usable width
-	}
-
-	if optimalWidth > maxAllowedWidth {
-		return maxAllowedWidth
-	}
-
-	// Ensure minimum width for readability
-	if optimalWidth < 60 {
-		return 60
-	}
-
-	return optimalWidth
-}
-
 // renameFile renames a file in S3
 func (m Model) renameFile(oldKey, newFilename string) tea.Cmd {
 	return tea.Cmd(func() tea.Msg {
 		// Construct the new key with the same path but new filename
-		var newKey string
-		if strings.Contains(oldKey, "/") {
-			// File is in a subdirectory
-			parts := strings.Split(oldKey, "/")
-			parts[len(parts)-1] = newFilename // Replace the filename
-			newKey = strings.Join(parts, "/")
-		} else {
-			// File is in root
-			newKey = newFilename
-		}
+		newKey := parentPrefix(oldKey) + newFilename
 
 		// Check if the new name already exists
 		for _, obj := range m.objects {
@@ -2013,104 +2992,103 @@ func (m Model) renameFile(oldKey, newFilename string) tea.Cmd {
 			return fileRenamedMsg{err: err}
 		}
 
-		// Update yanked files references if the renamed file was yanked
-		for i, yankedKey := range m.yankedFiles {
-			if yankedKey == oldKey {
-				m.yankedFiles[i] = newKey
-				break
-			}
-		}
-
 		return fileRenamedMsg{
+			client: m.s3Client,
+			bucket: m.bucket,
 			oldKey: oldKey,
 			newKey: newKey,
 		}
 	})
 }
 
-// pasteFiles copies all yanked files to the current location
+// pasteFiles copies all yanked files and folders to the current location
 func (m Model) pasteFiles() tea.Cmd {
 	return tea.Cmd(func() tea.Msg {
-		if len(m.yankedFiles) == 0 {
-			return fileCopiedMsg{err: fmt.Errorf("no files yanked for copying")}
+		if len(m.yankedFiles) == 0 && len(m.cutFiles) == 0 {
+			return transferStartedMsg{err: fmt.Errorf("no files yanked for copying")}
 		}
 
-		var copiedFiles []string
-		var errors []string
-
-		for _, yankedFile := range m.yankedFiles {
-			// Get the filename from the yanked file
-			filename := filepath.Base(yankedFile)
-
-			// Construct destination key
-			var destKey string
-			if m.currentPath != "" {
-				destKey = m.currentPath + "/" + filename
-			} else {
-				destKey = filename
+		// Names already present in the current location
+		taken := make(map[string]bool)
+		for _, obj := range m.objects {
+			taken[keyName(obj.Key)] = true
+		}
+
+		ctx := context.Background()
+		samePlace := m.yankClient.SameEndpoint(m.s3Client) && m.yankBucket == m.bucket
+		var items []transferItem
+		var moved []string
+		marked := append(append([]string{}, m.yankedFiles...), m.cutFiles...)
+		for i, yankedKey := range marked {
+			isDir := strings.HasSuffix(yankedKey, "/")
+			move := i >= len(m.yankedFiles)
+			if move && samePlace && parentPrefix(yankedKey) == m.currentPath {
+				// Moving an item onto itself is a no-op
+				moved = append(moved, yankedKey)
+				continue
+			}
+			if isDir && samePlace && strings.HasPrefix(m.currentPath, yankedKey) {
+				return transferStartedMsg{err: fmt.Errorf("cannot paste folder '%s' into itself", displayKey(keyName(yankedKey)))}
 			}
 
-			// Check if file already exists in current location
-			for _, obj := range m.objects {
-				if obj.Key == destKey {
-					// File exists, create a new name with suffix
-					ext := filepath.Ext(filename)
-					nameWithoutExt := strings.TrimSuffix(filename, ext)
-
-					// Find a unique name by adding numbers
-					counter := 1
-					for {
-						newFilename := fmt.Sprintf("%s_copy_%d%s", nameWithoutExt, counter, ext)
-						if m.currentPath != "" {
-							destKey = m.currentPath + "/" + newFilename
-						} else {
-							destKey = newFilename
-						}
+			// Resolve name conflicts by adding a _copy_N suffix
+			name := uniqueName(keyName(yankedKey), isDir, taken)
+			taken[name] = true
 
-						// Check if this new name exists
-						exists := false
-						for _, existingObj := range m.objects {
-							if existingObj.Key == destKey {
-								exists = true
-								break
-							}
-						}
-						if !exists {
-							break
-						}
-						counter++
-					}
-					break
-				}
+			dstKey := m.currentPath + name
+			if isDir {
+				dstKey += "/"
 			}
-
-			// Perform the copy operation
-			err := m.s3Client.CopyObject(context.Background(), m.bucket, yankedFile, destKey)
+			expanded, err := expandTransferItem(ctx, m.yankClient, m.yankBucket, yankedKey, dstKey)
 			if err != nil {
-				errors = append(errors, fmt.Sprintf("%s: %v", filepath.Base(yankedFile), err))
-			} else {
-				copiedFiles = append(copiedFiles, filepath.Base(destKey))
+				return transferStartedMsg{err: err}
+			}
+			for j := range expanded {
+				expanded[j].move = move
+				if move {
+					expanded[j].cut = yankedKey
+				}
 			}
+			items = append(items, expanded...)
+		}
+
+		return startTransfer(transferJob{
+			title:     fmt.Sprintf("Pasting %d item(s) to /%s", len(marked), displayKey(m.currentPath)),
+			srcClient: m.yankClient,
+			srcBucket: m.yankBucket,
+			dstClient: m.s3Client,
+			dstBucket: m.bucket,
+			items:     items,
+			moved:     moved,
+		})
+	})
+}
+
+// renameFolder moves every object under a folder prefix to a new folder name
+func (m Model) renameFolder(oldPrefix, newName string) tea.Cmd {
+	return tea.Cmd(func() tea.Msg {
+		if strings.Contains(newName, "/") {
+			return transferStartedMsg{err: fmt.Errorf("folder name cannot contain '/'")}
 		}
 
-		// Return result with summary
-		if len(errors) > 0 {
-			errorMsg := fmt.Sprintf("Failed to copy %d file(s): %s", len(errors), strings.Join(errors, ", "))
-			if len(copiedFiles) > 0 {
-				errorMsg += fmt.Sprintf(". Successfully copied: %s", strings.Join(copiedFiles, ", "))
+		newPrefix := m.currentPath + newName + "/"
+		for _, obj := range m.objects {
+			if keyName(obj.Key) == newName {
+				return transferStartedMsg{err: fmt.Errorf("'%s' already exists", displayKey(newName))}
 			}
-			return fileCopiedMsg{err: fmt.Errorf(errorMsg)}
 		}
 
-		return fileCopiedMsg{
-			sourceKey: fmt.Sprintf("%d files", len(m.yankedFiles)),
-			destKey:   strings.Join(copiedFiles, ", "),
+		job, err := planPrefixMove(context.Background(), m.s3Client, m.bucket, oldPrefix, newPrefix)
+		if err != nil {
+			return transferStartedMsg{err: err}
 		}
+		return startTransfer(job)
 	})
 }
 
 // calculateDirStats calculates directory statistics with timeouts
 func (m Model) calculateDirStats(dirKey string) tea.Cmd {
+	pane := m.paneID
 	return tea.Cmd(func() tea.Msg {
 		prefix := dirKey
 		if prefix != "" && !strings.HasSuffix(prefix, "/") {
@@ -2120,7 +3098,7 @@ func (m Model) calculateDirStats(dirKey string) tea.Cmd {
 		// Channel for size calculation
 		sizeChan := make(chan int64, 1)
 		sizeErrChan := make(chan error, 1)
-		
+
 		// Channel for last modified calculation
 		dateChan := make(chan string, 1)
 		dateErrChan := make(chan error, 1)
@@ -2187,6 +3165,7 @@ func (m Model) calculateDirStats(dirKey string) tea.Cmd {
 		}
 
 		return dirStatsMsg{
+			pane:         pane,
 			dirKey:       dirKey,
 			size:         size,
 			lastModified: lastModified,
@@ -2196,6 +3175,62 @@ func (m Model) calculateDirStats(dirKey string) tea.Cmd {
 	})
 }
 
+// openPane connects to the bucket described by spec ("bucket" or "profile:bucket")
+func (m Model) openPane(spec string) tea.Cmd {
+	return tea.Cmd(func() tea.Msg {
+		profile := "default"
+		bucket := spec
+		if i := strings.Index(spec, ":"); i >= 0 {
+			profile = spec[:i]
+			bucket = spec[i+1:]
+		}
+		if bucket == "" {
+			return paneOpenedMsg{err: fmt.Errorf("bucket name cannot be empty")}
+		}
+
+		client := m.s3Client
+		if profile != m.profile {
+			config, err := LoadS3ConfigProfile(profile)
+			if err != nil {
+				return paneOpenedMsg{err: err}
+			}
+			client, err = NewS3Client(config)
+			if err != nil {
+				return paneOpenedMsg{err: err}
+			}
+		}
+
+		if err := client.HeadBucket(context.Background(), bucket); err != nil {
+			return paneOpenedMsg{err: err}
+		}
+
+		return paneOpenedMsg{pane: paneState{
+			s3Client:      client,
+			profile:       profile,
+			bucket:        bucket,
+			dirStatsCache: make(map[string]DirStats),
+			loading:       true,
+		}}
+	})
+}
+
+// padRight pads s with spaces to the given display width
+func padRight(s string, width int) string {
+	if w := lipgloss.Width(s); w < width {
+		return s + strings.Repeat(" ", width-w)
+	}
+	return s
+}
+
+// truncateString shortens s to at most width runes, adding an ellipsis when cut
+func truncateString(s string, width int) string {
+	if width <= 3 || utf8.RuneCountInString(s) <= width {
+		return s
+	}
+	runes := []rune(s)
+	return string(runes[:width-3]) + "..."
+}
+
 // formatSize formats file size in human-readable format
 func formatSize(size int64) string {
 	const unit = 1024
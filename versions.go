@@ -0,0 +1,326 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// maxListedVersions bounds the versions view of a large prefix
+const maxListedVersions = 5000
+
+// Messages for the versions view
+type versionsLoadedMsg struct {
+	prefix    string
+	versions  []ObjectVersion
+	truncated bool
+	err       error
+}
+
+type versionActionMsg struct {
+	message string
+	err     error
+}
+
+// shortVersion abbreviates a version ID for titles and file names
+func shortVersion(versionID string) string {
+	if len(versionID) > 12 {
+		return versionID[:12]
+	}
+	return versionID
+}
+
+// loadVersions lists the versions of a key, or of every object under a
+// prefix (ending in "/")
+func (m Model) loadVersions(prefix string) tea.Cmd {
+	return tea.Cmd(func() tea.Msg {
+		var versions []ObjectVersion
+		var truncated bool
+		var err error
+		if strings.HasSuffix(prefix, "/") {
+			versions, truncated, err = m.s3Client.ListObjectVersions(context.Background(), m.bucket, prefix, maxListedVersions)
+		} else {
+			versions, err = m.s3Client.ListKeyVersions(context.Background(), m.bucket, prefix)
+		}
+		if err != nil {
+			return versionsLoadedMsg{prefix: prefix, err: err}
+		}
+		if len(versions) == 0 {
+			return versionsLoadedMsg{prefix: prefix, err: fmt.Errorf("no versions found for '%s'", displayKey(prefix))}
+		}
+		return versionsLoadedMsg{prefix: prefix, versions: versions, truncated: truncated}
+	})
+}
+
+// applyVersionsLoaded opens or refreshes the versions view
+func (m Model) applyVersionsLoaded(msg versionsLoadedMsg) (tea.Model, tea.Cmd) {
+	m.loading = false
+	if msg.err != nil {
+		m.err = msg.err
+		return m, nil
+	}
+	if m.viewMode != ViewVersions || m.versionsPrefix != msg.prefix {
+		m.versionsCursor = 0
+	}
+	m.versionsPrefix = msg.prefix
+	m.versions = msg.versions
+	m.versionsTruncated = msg.truncated
+	m.versionsCursor = min(m.versionsCursor, len(m.versions)-1)
+	m.viewMode = ViewVersions
+	m.err = nil
+	return m, nil
+}
+
+// applyVersionAction reports a change made from the versions view and lists the versions again
+func (m Model) applyVersionAction(msg versionActionMsg) (tea.Model, tea.Cmd) {
+	m.loading = false
+	if msg.err != nil {
+		m.err = msg.err
+		m.statusMessage = ""
+	} else {
+		m.err = nil
+		m.statusMessage = msg.message
+	}
+	return m, tea.Batch(m.loadVersions(m.versionsPrefix), m.loadObjects())
+}
+
+// latestVersion returns the newest entry listed for a key
+func (m Model) latestVersion(key string) (ObjectVersion, bool) {
+	for _, v := range m.versions {
+		if v.Key == key && v.IsLatest {
+			return v, true
+		}
+	}
+	return ObjectVersion{}, false
+}
+
+// downloadVersion saves a version of an object to the current directory,
+// naming the file after the version so the current object is not overwritten
+func (m Model) downloadVersion(v ObjectVersion) tea.Cmd {
+	return tea.Cmd(func() tea.Msg {
+		body, err := m.s3Client.GetObjectVersionStream(context.Background(), m.bucket, v.Key, v.VersionID)
+		if err != nil {
+			return fileDownloadedMsg{err: err}
+		}
+		defer body.Close()
+
+		name := localFileName(v.Key)
+		ext := filepath.Ext(name)
+		filename := fmt.Sprintf("%s.%s%s", strings.TrimSuffix(name, ext), localFileName(shortVersion(v.VersionID)), ext)
+
+		file, err := os.Create(filename)
+		if err != nil {
+			return fileDownloadedMsg{err: fmt.Errorf("failed to create file '%s': %w", filename, err)}
+		}
+		if _, err := io.Copy(file, body); err != nil {
+			file.Close()
+			os.Remove(filename)
+			return fileDownloadedMsg{err: fmt.Errorf("failed to download version: %w", err)}
+		}
+		if err := file.Close(); err != nil {
+			return fileDownloadedMsg{err: fmt.Errorf("failed to write file '%s': %w", filename, err)}
+		}
+		return fileDownloadedMsg{filename: filename}
+	})
+}
+
+// restoreVersion copies an old version over the current object
+func (m Model) restoreVersion(v ObjectVersion) tea.Cmd {
+	return tea.Cmd(func() tea.Msg {
+		if err := m.s3Client.RestoreObjectVersion(context.Background(), m.bucket, v.Key, v.VersionID); err != nil {
+			return versionActionMsg{err: err}
+		}
+		return versionActionMsg{message: fmt.Sprintf("✓ Restored version %s of '%s'", shortVersion(v.VersionID), displayKey(keyName(v.Key)))}
+	})
+}
+
+// undeleteVersion removes a delete marker, bringing back the version before it
+func (m Model) undeleteVersion(marker ObjectVersion) tea.Cmd {
+	return tea.Cmd(func() tea.Msg {
+		if err := m.s3Client.DeleteObjectVersion(context.Background(), m.bucket, marker.Key, marker.VersionID); err != nil {
+			return versionActionMsg{err: err}
+		}
+		return versionActionMsg{message: fmt.Sprintf("✓ Undeleted '%s'", displayKey(keyName(marker.Key)))}
+	})
+}
+
+// deleteVersion permanently deletes a version or delete marker
+func (m Model) deleteVersion(v ObjectVersion) tea.Cmd {
+	return tea.Cmd(func() tea.Msg {
+		if err := m.s3Client.DeleteObjectVersion(context.Background(), m.bucket, v.Key, v.VersionID); err != nil {
+			return versionActionMsg{err: err}
+		}
+		return versionActionMsg{message: fmt.Sprintf("✓ Permanently deleted version %s of '%s'", shortVersion(v.VersionID), displayKey(keyName(v.Key)))}
+	})
+}
+
+// updateVersions handles versions view updates
+func (m Model) updateVersions(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c", "q":
+		return m, tea.Quit
+	case "esc", "backspace", "h", "left":
+		m.viewMode = ViewBrowser
+		m.versions = nil
+		m.err = nil
+		m.statusMessage = ""
+		return m, nil
+	case "up", "k":
+		if m.versionsCursor > 0 {
+			m.versionsCursor--
+		}
+		return m, nil
+	case "down", "j":
+		if m.versionsCursor < len(m.versions)-1 {
+			m.versionsCursor++
+		}
+		return m, nil
+	case "g", "home":
+		m.versionsCursor = 0
+		return m, nil
+	case "G", "end":
+		m.versionsCursor = len(m.versions) - 1
+		return m, nil
+	case "R", "ctrl+r":
+		m.loading = true
+		return m, m.loadVersions(m.versionsPrefix)
+	}
+
+	if len(m.versions) == 0 {
+		return m, nil
+	}
+	v := m.versions[m.versionsCursor]
+	switch msg.String() {
+	case "enter", "l", "o":
+		if v.DeleteMarker {
+			m.err = fmt.Errorf("delete markers have no content")
+			return m, nil
+		}
+		m.err = nil
+		m.statusMessage = ""
+		return m, m.previewVersionContent(v.Key, v.VersionID)
+	case "d":
+		if v.DeleteMarker {
+			m.err = fmt.Errorf("delete markers have no content")
+			return m, nil
+		}
+		m.loading = true
+		m.err = nil
+		m.statusMessage = ""
+		return m, m.downloadVersion(v)
+	case "r":
+		switch {
+		case v.DeleteMarker:
+			m.err = fmt.Errorf("select the version to restore, not a delete marker (u removes the marker)")
+		case v.IsLatest:
+			m.err = fmt.Errorf("this is already the current version")
+		default:
+			m.confirmAction = "version_restore"
+			m.confirmTarget = v.Key
+			m.confirmData = v
+			m.viewMode = ViewConfirm
+			m.err = nil
+			m.statusMessage = ""
+		}
+	case "u":
+		latest, ok := m.latestVersion(v.Key)
+		if !ok || !latest.DeleteMarker {
+			m.err = fmt.Errorf("'%s' is not deleted", displayKey(keyName(v.Key)))
+			return m, nil
+		}
+		m.loading = true
+		m.err = nil
+		return m, m.undeleteVersion(latest)
+	case "X":
+		// Permanent deletes cannot be undone, so the name has to be typed
+		m.versionTarget = v
+		label := fmt.Sprintf("Type '%s' to delete version %s forever:", keyName(v.Key), shortVersion(v.VersionID))
+		if v.DeleteMarker {
+			label = fmt.Sprintf("Type '%s' to delete this delete marker forever:", keyName(v.Key))
+		}
+		m.openPrompt("version_delete", "Permanently Delete Version", label, "")
+	}
+	return m, nil
+}
+
+// viewVersions renders the versions view
+func (m Model) viewVersions() string {
+	var s strings.Builder
+
+	title := fmt.Sprintf("Versions: /%s", displayKey(m.versionsPrefix))
+	if m.versionsTruncated {
+		title += fmt.Sprintf(" (first %d)", len(m.versions))
+	}
+	s.WriteString(titleStyle.Render(title))
+	s.WriteString("\n\n")
+
+	// Keys are shown relative to the listed folder
+	base := parentPrefix(m.versionsPrefix)
+	if strings.HasSuffix(m.versionsPrefix, "/") {
+		base = m.versionsPrefix
+	}
+	nameWidth := 0
+	for _, v := range m.versions {
+		nameWidth = max(nameWidth, len(displayKey(strings.TrimPrefix(v.Key, base))))
+	}
+	nameWidth = min(nameWidth, max(m.width-90, 20))
+
+	var content strings.Builder
+	height := max(m.height-12, 5)
+	start := 0
+	if m.versionsCursor >= height {
+		start = m.versionsCursor - height + 1
+	}
+	for i := start; i < len(m.versions) && i < start+height; i++ {
+		v := m.versions[i]
+		cursor := " "
+		if i == m.versionsCursor {
+			cursor = ">"
+		}
+		state, size := "", formatSize(v.Size)
+		switch {
+		case v.DeleteMarker && v.IsLatest:
+			state, size = "deleted", "-"
+		case v.DeleteMarker:
+			state, size = "marker", "-"
+		case v.IsLatest:
+			state = "current"
+		}
+		name := truncateString(displayKey(strings.TrimPrefix(v.Key, base)), nameWidth)
+		line := fmt.Sprintf("%s %-*s  %-32s  %-7s  %10s  %s", cursor, nameWidth, name,
+			truncateString(v.VersionID, 32), state, size, v.LastModified.Local().Format("2006-01-02 15:04:05"))
+		switch {
+		case i == m.versionsCursor:
+			line = selectedStyle.Render(line)
+		case v.DeleteMarker:
+			line = helpStyle.Render(line)
+		case v.IsLatest:
+			line = fileStyle.Render(line)
+		}
+		content.WriteString(line + "\n")
+	}
+
+	if m.loading {
+		content.WriteString("\nWorking...")
+	} else if m.err != nil {
+		content.WriteString("\n" + errorStyle.Render(fmt.Sprintf("Error: %s", m.err.Error())))
+	} else if m.statusMessage != "" {
+		content.WriteString("\n" + successStyle.Render(m.statusMessage))
+	}
+	s.WriteString(previewStyle.Render(strings.TrimSuffix(content.String(), "\n")))
+
+	s.WriteString("\n\n")
+	s.WriteString(helpStyle.Render("↑/k,↓/j: move • enter: preview • d: download • r: restore • u: undelete • X: delete forever • R: refresh • esc: back"))
+
+	if m.width > 0 && m.height > 0 {
+		centered := centerStyle.Width(m.width).Render(lipgloss.NewStyle().Align(lipgloss.Left).Render(s.String()))
+		return verticalCenterStyle.Height(m.height).Render(centered)
+	}
+	return s.String()
+}